@@ -0,0 +1,197 @@
+// Package absence keeps the personal leave of the user, vacations, sick
+// days and half days, in a local JSON file.
+package absence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alinsimion/jira-cli/period"
+)
+
+type Kind string
+
+const (
+	KindVacation string = "vacation"
+	KindSick     string = "sick"
+	KindHalfDay  string = "halfday"
+)
+
+func (e *Kind) String() string {
+	return string(*e)
+}
+
+func (e *Kind) Set(v string) error {
+	switch v {
+	case KindVacation, KindSick, KindHalfDay:
+		*e = Kind(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s", []string{KindVacation, KindSick, KindHalfDay})
+	}
+}
+
+func (e *Kind) Type() string {
+	return "Kind"
+}
+
+// Fraction is the part of a working day the absence takes.
+func (e Kind) Fraction() float32 {
+	if string(e) == KindHalfDay {
+		return 0.5
+	}
+
+	return 1
+}
+
+// Mark is how the absence is shown in the worklog table.
+func (e Kind) Mark() string {
+	switch string(e) {
+	case KindSick:
+		return "S"
+	case KindHalfDay:
+		return "V/2"
+	default:
+		return "V"
+	}
+}
+
+// Absence is leave taken from From to To, both included, in the yyyy-mm-dd format.
+type Absence struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind Kind   `json:"kind"`
+	Note string `json:"note,omitempty"`
+}
+
+// New builds the absence covering the days of r.
+func New(r period.Range, kind Kind, note string) Absence {
+	return Absence{
+		From: r.From.Format(time.DateOnly),
+		To:   r.To.Format(time.DateOnly),
+		Kind: kind,
+		Note: note,
+	}
+}
+
+// Contains reports whether the absence covers the day of date.
+func (a Absence) Contains(date time.Time) bool {
+	day := date.Format(time.DateOnly)
+
+	return a.From <= day && day <= a.To
+}
+
+// During reports whether the absence covers some day of r.
+func (a Absence) During(r period.Range) bool {
+	return a.overlaps(New(r, a.Kind, ""))
+}
+
+func (a Absence) overlaps(other Absence) bool {
+	return a.From <= other.To && other.From <= a.To
+}
+
+func (a Absence) String() string {
+	s := fmt.Sprintf("%s - %s %s", a.From, a.To, a.Kind)
+	if a.Note != "" {
+		s += ", " + a.Note
+	}
+
+	return s
+}
+
+// Store is the list of absences saved in a file. A nil Store has no absences.
+type Store struct {
+	path     string
+	Absences []Absence
+}
+
+// DefaultPath is where absences are kept unless told otherwise.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "jira-cli", "absences.json")
+}
+
+// Open reads the absences saved at path. A missing file holds no absences.
+func Open(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.Absences); err != nil {
+		return nil, fmt.Errorf("reading absences from %s: %w", path, err)
+	}
+
+	return store, nil
+}
+
+// Add records absence, which must not overlap an absence already recorded.
+func (s *Store) Add(absence Absence) error {
+	for _, other := range s.Absences {
+		if absence.overlaps(other) {
+			return fmt.Errorf("absence %s overlaps %s", absence, other)
+		}
+	}
+
+	s.Absences = append(s.Absences, absence)
+	sort.Slice(s.Absences, func(i, j int) bool {
+		return s.Absences[i].From < s.Absences[j].From
+	})
+
+	return nil
+}
+
+// Remove forgets the absence covering the day of date.
+func (s *Store) Remove(date time.Time) (Absence, error) {
+	for i, absence := range s.Absences {
+		if absence.Contains(date) {
+			s.Absences = append(s.Absences[:i], s.Absences[i+1:]...)
+			return absence, nil
+		}
+	}
+
+	return Absence{}, fmt.Errorf("no absence on %s", date.Format(time.DateOnly))
+}
+
+// On returns the absence covering the day of date.
+func (s *Store) On(date time.Time) (Absence, bool) {
+	if s == nil {
+		return Absence{}, false
+	}
+
+	for _, absence := range s.Absences {
+		if absence.Contains(date) {
+			return absence, true
+		}
+	}
+
+	return Absence{}, false
+}
+
+// Save writes the absences back to the file they were read from.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s.Absences, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o644)
+}
@@ -0,0 +1,384 @@
+// Package adf writes Markdown as an Atlassian Document, the rich text format
+// Jira Cloud takes worklog comments in.
+package adf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Node is a node of an Atlassian Document, ready to be sent as JSON.
+type Node = map[string]any
+
+var (
+	heading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rule     = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	fence    = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+-]*)\\s*$")
+	listItem = regexp.MustCompile(`^(\s*)([-*+]|(\d{1,9})[.)])\s+(.*)$`)
+	quote    = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	bareURL  = regexp.MustCompile(`^https?://[^\s<>]+`)
+)
+
+// FromMarkdown converts markdown to a document. It understands paragraphs,
+// line breaks, headings, bullet and numbered lists nested by indentation,
+// fenced code blocks, quotes, rules, and inline **bold**, *italic*,
+// ~~strike~~, `code`, [links](https://example.com) and bare URLs.
+func FromMarkdown(markdown string) Node {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.ReplaceAll(markdown, "\t", "    ")
+
+	return Node{
+		"type":    "doc",
+		"version": 1,
+		"content": blocks(strings.Split(markdown, "\n")),
+	}
+}
+
+// blocks converts lines to block nodes.
+func blocks(lines []string) []Node {
+	nodes := []Node{}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fence.MatchString(line):
+			match := fence.FindStringSubmatch(line)
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), match[1]) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			nodes = append(nodes, codeBlock(match[2], strings.Join(code, "\n")))
+
+		case heading.MatchString(line):
+			match := heading.FindStringSubmatch(line)
+			nodes = append(nodes, Node{
+				"type":    "heading",
+				"attrs":   Node{"level": len(match[1])},
+				"content": inline(match[2]),
+			})
+			i++
+
+		case rule.MatchString(line):
+			nodes = append(nodes, Node{"type": "rule"})
+			i++
+
+		case quote.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && quote.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quote.FindStringSubmatch(lines[i])[1])
+			}
+			nodes = append(nodes, Node{"type": "blockquote", "content": nestable(blocks(quoted))})
+
+		case listItem.MatchString(line):
+			var list Node
+			list, i = listAt(lines, i)
+			nodes = append(nodes, list)
+
+		default:
+			var text []string
+			for ; i < len(lines) && !startsBlock(lines[i]); i++ {
+				text = append(text, strings.TrimSpace(lines[i]))
+			}
+			nodes = append(nodes, Node{"type": "paragraph", "content": inlineLines(text)})
+		}
+	}
+
+	return nodes
+}
+
+// nestable rewrites the blocks a quote or a list item cannot hold, which
+// only take paragraphs, lists and code: headings become bold paragraphs,
+// rules are dropped and quotes give up their content.
+func nestable(nodes []Node) []Node {
+	nested := []Node{}
+
+	for _, node := range nodes {
+		switch node["type"] {
+		case "heading":
+			content, _ := node["content"].([]Node)
+			nested = append(nested, Node{"type": "paragraph", "content": marked(content, Node{"type": "strong"})})
+		case "rule":
+		case "blockquote":
+			content, _ := node["content"].([]Node)
+			nested = append(nested, nestable(content)...)
+		default:
+			nested = append(nested, node)
+		}
+	}
+
+	return nested
+}
+
+// startsBlock reports whether line ends the paragraph before it.
+func startsBlock(line string) bool {
+	return strings.TrimSpace(line) == "" || fence.MatchString(line) || heading.MatchString(line) ||
+		rule.MatchString(line) || quote.MatchString(line) || listItem.MatchString(line)
+}
+
+// listAt reads the list whose first item is lines[start], and returns it with
+// the index of the first line after it. Lines indented past the marker of an
+// item belong to it, nested lists included.
+func listAt(lines []string, start int) (Node, int) {
+	first := listItem.FindStringSubmatch(lines[start])
+	indent := len(first[1])
+	ordered := first[3] != ""
+
+	list := Node{"type": "bulletList"}
+	if ordered {
+		order, _ := strconv.Atoi(first[3])
+		list = Node{"type": "orderedList", "attrs": Node{"order": order}}
+	}
+
+	items := []Node{}
+	i := start
+	for i < len(lines) {
+		match := listItem.FindStringSubmatch(lines[i])
+		if match == nil || len(match[1]) != indent || (match[3] != "") != ordered {
+			break
+		}
+
+		body := []string{match[4]}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			trimmed := strings.TrimLeft(line, " ")
+			depth := len(line) - len(trimmed)
+
+			if trimmed == "" {
+				// a blank line ends the list unless it goes on after it
+				next := i + 1
+				for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+					next++
+				}
+				if next < len(lines) && leadingSpaces(lines[next]) > indent {
+					body = append(body, "")
+					continue
+				}
+				break
+			}
+
+			if depth <= indent && startsBlock(line) {
+				break
+			}
+
+			body = append(body, dedent(line, indent+2))
+		}
+
+		// an item starts with a paragraph or code, an empty one when it has no text
+		content := nestable(blocks(body))
+		if len(content) == 0 || content[0]["type"] != "paragraph" && content[0]["type"] != "codeBlock" {
+			content = append([]Node{{"type": "paragraph", "content": []Node{}}}, content...)
+		}
+		items = append(items, Node{"type": "listItem", "content": content})
+
+		// blank lines between items of the same list
+		next := i
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next < len(lines) && next != i {
+			if match := listItem.FindStringSubmatch(lines[next]); match != nil && len(match[1]) == indent && (match[3] != "") == ordered {
+				i = next
+			}
+		}
+	}
+
+	list["content"] = items
+
+	return list, i
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// dedent strips up to n spaces from the start of line.
+func dedent(line string, n int) string {
+	return line[min(n, leadingSpaces(line)):]
+}
+
+func codeBlock(language string, code string) Node {
+	node := Node{"type": "codeBlock"}
+	if language != "" {
+		node["attrs"] = Node{"language": language}
+	}
+	if code != "" {
+		node["content"] = []Node{{"type": "text", "text": code}}
+	}
+
+	return node
+}
+
+// inlineLines converts the lines of a paragraph, keeping them apart with line breaks.
+func inlineLines(lines []string) []Node {
+	nodes := []Node{}
+	for i, line := range lines {
+		if i > 0 {
+			nodes = append(nodes, Node{"type": "hardBreak"})
+		}
+		nodes = append(nodes, inline(line)...)
+	}
+
+	return nodes
+}
+
+// inline converts the text of a paragraph, a heading or a list item.
+func inline(text string) []Node {
+	nodes := []Node{}
+	plain := strings.Builder{}
+
+	flush := func() {
+		if plain.Len() > 0 {
+			nodes = append(nodes, Node{"type": "text", "text": plain.String()})
+			plain.Reset()
+		}
+	}
+	add := func(inner []Node) {
+		flush()
+		nodes = append(nodes, inner...)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		wordStart := i == 0 || !isWordChar(text[i-1])
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_~[]()#>-+.!", rune(rest[1])):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			if end := strings.Index(rest[1:], "`"); end > 0 {
+				add(marked([]Node{{"type": "text", "text": rest[1 : end+1]}}, Node{"type": "code"}))
+				i += end + 2
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__") && wordStart:
+			if end := closing(rest[2:], rest[:2]); end > 0 {
+				add(marked(inline(rest[2:end+2]), Node{"type": "strong"}))
+				i += end + 4
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if end := closing(rest[2:], "~~"); end > 0 {
+				add(marked(inline(rest[2:end+2]), Node{"type": "strike"}))
+				i += end + 4
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_' && wordStart:
+			if end := closing(rest[1:], rest[:1]); end > 0 {
+				add(marked(inline(rest[1:end+1]), Node{"type": "em"}))
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '[':
+			if label, href, size, ok := link(rest); ok {
+				add(marked(inline(label), Node{"type": "link", "attrs": Node{"href": href}}))
+				i += size
+				continue
+			}
+
+		case wordStart && bareURL.MatchString(rest):
+			href := strings.TrimRight(bareURL.FindString(rest), ".,;:!?)")
+			add(marked([]Node{{"type": "text", "text": href}}, Node{"type": "link", "attrs": Node{"href": href}}))
+			i += len(href)
+			continue
+		}
+
+		plain.WriteByte(rest[0])
+		i++
+	}
+	flush()
+
+	return nodes
+}
+
+// closing finds where delimiter closes the span text starts, which may
+// neither start nor end with a space. A single _ or __ must end a word.
+func closing(text string, delimiter string) int {
+	if text == "" || text[0] == ' ' {
+		return -1
+	}
+
+	for i := 1; i+len(delimiter) <= len(text); i++ {
+		if text[i-1] == '\\' || !strings.HasPrefix(text[i:], delimiter) || text[i-1] == ' ' {
+			continue
+		}
+		// ** inside a single * span, or the second * of a ** closing
+		if len(delimiter) == 1 && strings.HasPrefix(text[i:], delimiter+delimiter) {
+			i++
+			continue
+		}
+		if delimiter[0] == '_' && i+len(delimiter) < len(text) && isWordChar(text[i+len(delimiter)]) {
+			continue
+		}
+		return i
+	}
+
+	return -1
+}
+
+// link reads a [label](href) at the start of text and its size.
+func link(text string) (string, string, int, bool) {
+	labelEnd := strings.Index(text, "](")
+	if labelEnd < 1 {
+		return "", "", 0, false
+	}
+
+	hrefEnd := strings.IndexByte(text[labelEnd+2:], ')')
+	if hrefEnd < 1 {
+		return "", "", 0, false
+	}
+
+	href := strings.TrimSpace(text[labelEnd+2 : labelEnd+2+hrefEnd])
+	if strings.ContainsAny(href, " \n") {
+		return "", "", 0, false
+	}
+
+	return text[1:labelEnd], href, labelEnd + 3 + hrefEnd, true
+}
+
+// marked adds mark to every text node of nodes. Code only goes with links in
+// a document, so code keeps its single mark otherwise.
+func marked(nodes []Node, mark Node) []Node {
+	for _, node := range nodes {
+		if node["type"] != "text" {
+			continue
+		}
+
+		marks, _ := node["marks"].([]Node)
+		if mark["type"] != "link" && hasMark(marks, "code") {
+			continue
+		}
+		node["marks"] = append(marks, mark)
+	}
+
+	return nodes
+}
+
+func hasMark(marks []Node, kind string) bool {
+	for _, mark := range marks {
+		if mark["type"] == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,68 @@
+package adf
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "plain text",
+			markdown: "I did some work here",
+			want:     `[{"content":[{"text":"I did some work here","type":"text"}],"type":"paragraph"}]`,
+		},
+		{
+			name:     "empty list item",
+			markdown: "- ",
+			want:     `[{"content":[{"content":[{"content":[],"type":"paragraph"}],"type":"listItem"}],"type":"bulletList"}]`,
+		},
+		{
+			name:     "item starting with a nested list",
+			markdown: "- - a",
+			want:     `[{"content":[{"content":[{"content":[],"type":"paragraph"},{"content":[{"content":[{"content":[{"text":"a","type":"text"}],"type":"paragraph"}],"type":"listItem"}],"type":"bulletList"}],"type":"listItem"}],"type":"bulletList"}]`,
+		},
+		{
+			name:     "heading in a quote",
+			markdown: "> # h\n> text",
+			want:     `[{"content":[{"content":[{"marks":[{"type":"strong"}],"text":"h","type":"text"}],"type":"paragraph"},{"content":[{"text":"text","type":"text"}],"type":"paragraph"}],"type":"blockquote"}]`,
+		},
+		{
+			name:     "rule in a quote",
+			markdown: "> a\n> ---",
+			want:     `[{"content":[{"content":[{"text":"a","type":"text"}],"type":"paragraph"}],"type":"blockquote"}]`,
+		},
+		{
+			name:     "heading in a list item",
+			markdown: "- # title",
+			want:     `[{"content":[{"content":[{"content":[{"marks":[{"type":"strong"}],"text":"title","type":"text"}],"type":"paragraph"}],"type":"listItem"}],"type":"bulletList"}]`,
+		},
+		{
+			name:     "quote in a list item",
+			markdown: "- item\n\n  > quote",
+			want:     `[{"content":[{"content":[{"content":[{"text":"item","type":"text"}],"type":"paragraph"},{"content":[{"text":"quote","type":"text"}],"type":"paragraph"}],"type":"listItem"}],"type":"bulletList"}]`,
+		},
+		{
+			name:     "rule in a list item",
+			markdown: "- a\n\n  ---\n\n  b",
+			want:     `[{"content":[{"content":[{"content":[{"text":"a","type":"text"}],"type":"paragraph"},{"content":[{"text":"b","type":"text"}],"type":"paragraph"}],"type":"listItem"}],"type":"bulletList"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(FromMarkdown(tt.markdown)["content"])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("FromMarkdown(%q)\n got %s\nwant %s", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package adf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToMarkdown writes a document back as Markdown, the way FromMarkdown reads
+// it. doc is either built by FromMarkdown or decoded from JSON. Nodes
+// Markdown has no syntax for keep their text only.
+func ToMarkdown(doc any) string {
+	return strings.TrimRight(blocksMarkdown(children(doc)), "\n")
+}
+
+// blocksMarkdown writes block nodes apart by blank lines.
+func blocksMarkdown(nodes []map[string]any) string {
+	var parts []string
+
+	for _, node := range nodes {
+		var text string
+
+		switch node["type"] {
+		case "heading":
+			level, _ := number(attr(node, "level"))
+			text = strings.Repeat("#", max(level, 1)) + " " + inlineMarkdown(children(node))
+		case "rule":
+			text = "---"
+		case "codeBlock":
+			language, _ := attr(node, "language").(string)
+			text = "```" + language + "\n" + inlineText(children(node)) + "\n```"
+		case "blockquote":
+			lines := strings.Split(blocksMarkdown(children(node)), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			text = strings.Join(lines, "\n")
+		case "bulletList", "orderedList":
+			text = listMarkdown(node)
+		default:
+			text = inlineMarkdown(children(node))
+		}
+
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// listMarkdown writes a list, one item per line and the blocks of an item
+// after its first indented under it, apart by blank lines.
+func listMarkdown(list map[string]any) string {
+	order, ordered := number(attr(list, "order"))
+	if list["type"] == "orderedList" && !ordered {
+		order, ordered = 1, true
+	}
+
+	var items []string
+	for i, item := range children(list) {
+		marker := "- "
+		if list["type"] == "orderedList" {
+			marker = fmt.Sprintf("%d. ", order+i)
+		}
+
+		var blocks []string
+		for _, block := range children(item) {
+			blocks = append(blocks, blocksMarkdown([]map[string]any{block}))
+		}
+
+		items = append(items, marker+indentLines(strings.Join(blocks, "\n\n"), strings.Repeat(" ", len(marker))))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// inlineMarkdown writes text nodes with their marks, and line breaks.
+func inlineMarkdown(nodes []map[string]any) string {
+	var text strings.Builder
+
+	for _, node := range nodes {
+		if node["type"] == "hardBreak" {
+			text.WriteString("\n")
+			continue
+		}
+
+		value, _ := node["text"].(string)
+		if node["type"] != "text" {
+			value = inlineMarkdown(children(node))
+		}
+
+		for _, mark := range marks(node) {
+			switch mark["type"] {
+			case "code":
+				value = "`" + value + "`"
+			case "strong":
+				value = "**" + value + "**"
+			case "em":
+				value = "*" + value + "*"
+			case "strike":
+				value = "~~" + value + "~~"
+			case "link":
+				if href, _ := attr(mark, "href").(string); href != "" && href != value {
+					value = "[" + value + "](" + href + ")"
+				}
+			}
+		}
+
+		text.WriteString(value)
+	}
+
+	return text.String()
+}
+
+// inlineText is the text of nodes without any markup, as in code blocks.
+func inlineText(nodes []map[string]any) string {
+	var text strings.Builder
+	for _, node := range nodes {
+		value, _ := node["text"].(string)
+		text.WriteString(value)
+	}
+
+	return text.String()
+}
+
+// indentLines starts every line of text but the first and the blank ones with indent.
+func indentLines(text string, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// children are the content nodes of node, whether built here or decoded from JSON.
+func children(node any) []map[string]any {
+	object, _ := node.(map[string]any)
+	return nodeList(object["content"])
+}
+
+func marks(node map[string]any) []map[string]any {
+	return nodeList(node["marks"])
+}
+
+func nodeList(value any) []map[string]any {
+	switch value := value.(type) {
+	case []map[string]any:
+		return value
+	case []any:
+		nodes := make([]map[string]any, 0, len(value))
+		for _, item := range value {
+			if node, ok := item.(map[string]any); ok {
+				nodes = append(nodes, node)
+			}
+		}
+		return nodes
+	default:
+		return nil
+	}
+}
+
+func attr(node map[string]any, name string) any {
+	attrs, _ := node["attrs"].(map[string]any)
+	return attrs[name]
+}
+
+// number reads a numeric attribute, an int when built here and a float64
+// when decoded from JSON.
+func number(value any) (int, bool) {
+	switch value := value.(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,40 @@
+package adf
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToMarkdownRoundTrip(t *testing.T) {
+	tests := []string{
+		"Fixed the **login** bug\nsee [PR](https://example.com/pr/1) and https://example.com/a.",
+		"- one\n- two *em* and `code_x`\n\n  - nested\n- three",
+		"1. a\n2. b\n\n   para",
+		"```go\nfmt.Println(\"hi\")\n```",
+		"> quoted\n> more\n\n## Heading\n\n---\n\n~~gone~~",
+	}
+
+	for _, markdown := range tests {
+		doc := FromMarkdown(markdown)
+
+		// comments come back from Jira as JSON
+		data, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		got := ToMarkdown(decoded)
+		if got != markdown {
+			t.Errorf("ToMarkdown(FromMarkdown(%q)) = %q", markdown, got)
+		}
+
+		again, _ := json.Marshal(FromMarkdown(got))
+		if string(again) != string(data) {
+			t.Errorf("%q does not read back as the same document\n got %s\nwant %s", markdown, again, data)
+		}
+	}
+}
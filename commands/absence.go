@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/alinsimion/jira-cli/absence"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addAbsenceCommands adds the absence command, which keeps the user's
+// vacations, sick days and half days in the local absence file.
+func (ce CommandEngine) addAbsenceCommands() {
+	var Absence = &cobra.Command{
+		Use:   absenceCMD,
+		Short: "records your vacations, sick days and half days",
+		Long: `records your vacations, sick days and half days, which logging work for a period skips,
+or books on the issue given with --absence-issue`,
+	}
+
+	var Add = &cobra.Command{
+		Use:   "add",
+		Short: "records an absence",
+		Example: `absence add --from 22/07/2024 --to 02/08/2024 -n "summer holiday"
+absence add --from 14/10/2024 --kind sick
+absence add --from 18/10/2024 --kind halfday`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+			note, _ := cmd.Flags().GetString("note")
+
+			if to == "" {
+				to = from
+			}
+
+			fromDate, err := utils.ParseDate(from)
+			if err != nil {
+				return err
+			}
+
+			toDate, err := utils.ParseDate(to)
+			if err != nil {
+				return err
+			}
+
+			days, err := period.Between(fromDate, toDate)
+			if err != nil {
+				return err
+			}
+
+			kind := absence.Kind(cmd.Flags().Lookup("kind").Value.String())
+			if kind.Fraction() < 1 && days.From != days.To {
+				return fmt.Errorf("a %s absence lasts a single day", kind)
+			}
+
+			store, err := absenceStore(cmd)
+			if err != nil {
+				return err
+			}
+
+			away := absence.New(days, kind, note)
+			if err := store.Add(away); err != nil {
+				return err
+			}
+
+			if err := store.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Absence recorded: %s\n", away)
+
+			return nil
+		},
+	}
+
+	var List = &cobra.Command{
+		Use:   "list",
+		Short: "lists your absences",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := absenceStore(cmd)
+			if err != nil {
+				return err
+			}
+
+			for _, away := range store.Absences {
+				fmt.Println(away)
+			}
+
+			return nil
+		},
+	}
+
+	var Remove = &cobra.Command{
+		Use:     "remove",
+		Short:   "forgets the absence covering a date",
+		Example: `absence remove -d 23/07/2024`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, _ := cmd.Flags().GetString("date")
+
+			day, err := utils.ParseDate(date)
+			if err != nil {
+				return err
+			}
+
+			store, err := absenceStore(cmd)
+			if err != nil {
+				return err
+			}
+
+			away, err := store.Remove(day)
+			if err != nil {
+				return err
+			}
+
+			if err := store.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Absence removed: %s\n", away)
+
+			return nil
+		},
+	}
+
+	kind := absence.Kind(absence.KindVacation)
+	Add.Flags().String("from", "", "the first day of the absence, "+dateUsage)
+	Add.Flags().String("to", "", "the last day of the absence, "+dateUsage+", defaults to --from")
+	Add.Flags().Var(&kind, "kind", "can be one of 'vacation', 'sick' or 'halfday'")
+	Add.Flags().StringP("note", "n", "", "a note on the absence, used as the comment when it is booked")
+	Add.MarkFlagRequired("from")
+
+	Remove.Flags().StringP("date", "d", "", "a day of the absence, "+dateUsage)
+	Remove.MarkFlagRequired("date")
+
+	Absence.AddCommand(Add, List, Remove)
+	ce.RootCmd.AddCommand(Absence)
+
+	ce.AllCommands[absenceCMD] = Absence
+}
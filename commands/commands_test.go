@@ -0,0 +1,496 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinsimion/jira-cli/absence"
+	"github.com/alinsimion/jira-cli/jiratest"
+	"github.com/alinsimion/jira-cli/service"
+	"github.com/alinsimion/jira-cli/timer"
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// user is a Jira Server user, known by name only.
+var user = service.JiraUser{Name: "jdoe", DisplayName: "John Doe"}
+
+// monday is a working day, the week of it is the one the tests log work in.
+var monday = time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+
+// execute runs the command line args against js with a fresh root command,
+// the files the commands keep their state in being left in a temporary directory.
+func execute(t *testing.T, js service.JiraClient, args ...string) error {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv(utils.JIRA_JOURNAL_FILE, filepath.Join(dir, "journal.json"))
+	t.Setenv(utils.JIRA_ABSENCE_FILE, filepath.Join(dir, "absences.json"))
+	t.Setenv(utils.JIRA_TIMER_FILE, filepath.Join(dir, "timer.json"))
+	t.Setenv(utils.JIRA_HOLIDAYS_COUNTRY, "")
+	t.Setenv(utils.JIRA_WORK_WEEK, "")
+	t.Setenv(utils.JIRA_DATE_ORDER, "")
+
+	return run(js, args...)
+}
+
+// run runs args with a fresh root command in the environment execute set up.
+func run(js service.JiraClient, args ...string) error {
+	root := &cobra.Command{Use: "jira-cli", SilenceUsage: true, SilenceErrors: true}
+	ce := NewCommandEngine(root, js)
+
+	ce.RootCmd.SetArgs(args)
+
+	return ce.RootCmd.ExecuteContext(context.Background())
+}
+
+// logged lists the seconds of every worklog of issue, oldest first.
+func logged(t *testing.T, fs *service.FakeJiraService, issue string) []int {
+	t.Helper()
+
+	worklogs, err := fs.GetWorkLogsForIssue(context.Background(), issue, service.WorklogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seconds := []int{}
+	for _, worklog := range worklogs.WorkLogs {
+		seconds = append(seconds, int(worklog.TimeSpentSeconds))
+	}
+
+	return seconds
+}
+
+// count counts the requests of method sent to srv.
+func count(requests []string, method string) int {
+	n := 0
+	for _, request := range requests {
+		if strings.HasPrefix(request, method+" ") {
+			n++
+		}
+	}
+
+	return n
+}
+
+func worklog(author service.JiraUser, started time.Time, seconds int) service.WorklogResponseObject {
+	return service.WorklogResponseObject{
+		Id:               "seeded",
+		Author:           author,
+		Started:          utils.CustomTime{Time: started},
+		TimeSpentSeconds: float64(seconds),
+	}
+}
+
+func TestLogWork(t *testing.T) {
+	colleague := service.JiraUser{Name: "jroe", DisplayName: "Jane Roe"}
+
+	tests := []struct {
+		name     string
+		args     []string
+		worklogs []service.WorklogResponseObject
+		absences []absence.Absence
+		wantErr  bool
+		want     []int
+		// wantAbsent are the worklogs booked on the absence issue GAIA-2
+		wantAbsent []int
+	}{
+		{
+			name: "one day",
+			args: []string{"logwork", "-i", "GAIA-1", "-t", "2h", "-d", "2024-03-04"},
+			want: []int{7200},
+		},
+		{
+			name: "a range skips the weekend",
+			args: []string{"logwork", "-i", "GAIA-1", "-t", "1h", "--from", "2024-03-08", "--to", "2024-03-11"},
+			want: []int{3600, 3600},
+		},
+		{
+			name:    "a day off is refused",
+			args:    []string{"logwork", "-i", "GAIA-1", "-t", "1h", "-d", "2024-03-09"},
+			wantErr: true,
+			want:    []int{},
+		},
+		{
+			name: "a day off is allowed",
+			args: []string{"logwork", "-i", "GAIA-1", "-t", "1h", "-d", "2024-03-09", "--allow-weekend"},
+			want: []int{3600},
+		},
+		{
+			name: "dry run",
+			args: []string{"logwork", "-i", "GAIA-1", "-t", "1h", "-d", "2024-03-04", "--dry-run"},
+			want: []int{},
+		},
+		{
+			name:    "invalid date",
+			args:    []string{"logwork", "-i", "GAIA-1", "-t", "1h", "-d", "2024-13-45"},
+			wantErr: true,
+			want:    []int{},
+		},
+		{
+			name:    "unknown issue",
+			args:    []string{"logwork", "-i", "GAIA-3", "-t", "1h", "-d", "2024-03-04"},
+			wantErr: true,
+			want:    []int{},
+		},
+		{
+			// the work of others on the day does not count
+			name:     "top up",
+			args:     []string{"logwork", "-i", "GAIA-1", "-t", "6h", "-d", "2024-03-04", "--mode", "topup"},
+			worklogs: []service.WorklogResponseObject{worklog(user, monday, 3600), worklog(colleague, monday, 7200)},
+			want:     []int{3600, 7200, 18000},
+		},
+		{
+			name:     "skip logged",
+			args:     []string{"logwork", "-i", "GAIA-1", "-t", "6h", "-d", "2024-03-04", "--mode", "skip-logged"},
+			worklogs: []service.WorklogResponseObject{worklog(user, monday, 3600)},
+			want:     []int{3600},
+		},
+		{
+			name:     "a day off is skipped",
+			args:     []string{"logwork", "-i", "GAIA-1", "-t", "8h", "--from", "2024-03-04", "--to", "2024-03-05"},
+			absences: []absence.Absence{{From: "2024-03-04", To: "2024-03-04", Kind: absence.Kind(absence.KindVacation)}},
+			want:     []int{28800},
+		},
+		{
+			name:       "a day off is booked",
+			args:       []string{"logwork", "-i", "GAIA-1", "-t", "8h", "--from", "2024-03-04", "--to", "2024-03-05", "--absence-issue", "GAIA-2"},
+			absences:   []absence.Absence{{From: "2024-03-04", To: "2024-03-04", Kind: absence.Kind(absence.KindSick)}},
+			want:       []int{28800},
+			wantAbsent: []int{28800},
+		},
+		{
+			name:       "half a day off is split",
+			args:       []string{"logwork", "-i", "GAIA-1", "-t", "8h", "--from", "2024-03-04", "--to", "2024-03-05", "--absence-issue", "GAIA-2"},
+			absences:   []absence.Absence{{From: "2024-03-04", To: "2024-03-04", Kind: absence.Kind(absence.KindHalfDay)}},
+			want:       []int{14400, 28800},
+			wantAbsent: []int{14400},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := service.NewFakeJiraService(user,
+				service.Issue{Key: "GAIA-1", Summary: "Some issue", Worklogs: tt.worklogs},
+				service.Issue{Key: "GAIA-2", Summary: "Absences"},
+			)
+
+			args := tt.args
+			if len(tt.absences) > 0 {
+				path := filepath.Join(t.TempDir(), "absences.json")
+				store, err := absence.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, away := range tt.absences {
+					if err := store.Add(away); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := store.Save(); err != nil {
+					t.Fatal(err)
+				}
+				args = append(slices.Clone(args), "--absence-file", path)
+			}
+
+			err := execute(t, fs, args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+
+			if got := logged(t, fs, "GAIA-1"); !slices.Equal(got, tt.want) {
+				t.Errorf("got worklogs of %v seconds, want %v", got, tt.want)
+			}
+			if got := logged(t, fs, "GAIA-2"); !slices.Equal(got, tt.wantAbsent) {
+				t.Errorf("got absence worklogs of %v seconds, want %v", got, tt.wantAbsent)
+			}
+		})
+	}
+}
+
+func TestBrokenAbsenceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "absences.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "history", args: []string{"history"}},
+		{name: "worklog edit", args: []string{"worklog", "edit", "--id", "seeded", "-t", "2h"}},
+		{name: "logwork", args: []string{"logwork", "-i", "GAIA-1", "-t", "1h", "-d", "2024-03-04"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := service.NewFakeJiraService(user, service.Issue{
+				Key:      "GAIA-1",
+				Summary:  "Some issue",
+				Worklogs: []service.WorklogResponseObject{worklog(user, monday, 3600)},
+			})
+
+			err := execute(t, fs, append(slices.Clone(tt.args), "--absence-file", path)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		name string
+		args [][]string
+		want []int
+	}{
+		{
+			name: "logged work",
+			args: [][]string{{"logwork", "-i", "GAIA-1", "-t", "2h", "-d", "2024-03-04"}},
+			want: []int{3600},
+		},
+		{
+			name: "edited worklog",
+			args: [][]string{{"worklog", "edit", "--id", "seeded", "-t", "3h"}},
+			want: []int{3600},
+		},
+		{
+			name: "deleted worklog",
+			args: [][]string{{"worklog", "delete", "--id", "seeded"}},
+			want: []int{3600},
+		},
+		{
+			name: "the last run only",
+			args: [][]string{
+				{"logwork", "-i", "GAIA-1", "-t", "2h", "-d", "2024-03-04"},
+				{"logwork", "-i", "GAIA-1", "-t", "30m", "-d", "2024-03-05"},
+			},
+			want: []int{3600, 7200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := service.NewFakeJiraService(user, service.Issue{
+				Key:      "GAIA-1",
+				Summary:  "Some issue",
+				Worklogs: []service.WorklogResponseObject{worklog(user, monday, 3600)},
+			})
+
+			if err := execute(t, fs, tt.args[0]...); err != nil {
+				t.Fatal(err)
+			}
+			for _, args := range tt.args[1:] {
+				// runs are told apart by the millisecond they started at
+				time.Sleep(2 * time.Millisecond)
+				if err := run(fs, args...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			if err := run(fs, "undo"); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := logged(t, fs, "GAIA-1"); !slices.Equal(got, tt.want) {
+				t.Errorf("got worklogs of %v seconds, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUndoTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		// movedBack moves the issue back by hand before the undo
+		movedBack bool
+	}{
+		{name: "moved issue"},
+		{name: "issue moved back already", movedBack: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := service.NewFakeJiraService(user, service.Issue{Key: "GAIA-1", Summary: "Some issue"})
+
+			if err := execute(t, fs, "move", "GAIA-1", "In", "Progress"); err != nil {
+				t.Fatal(err)
+			}
+			if status := fs.Status("GAIA-1"); status != "In Progress" {
+				t.Fatalf("got status %s, want In Progress", status)
+			}
+
+			if tt.movedBack {
+				fs.Configure(service.DefaultConfig())
+				if err := fs.UpdateIssue(context.Background(), "GAIA-1", service.DefaultStatus); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			if err := run(fs, "undo"); err != nil {
+				t.Fatal(err)
+			}
+			if status := fs.Status("GAIA-1"); status != service.DefaultStatus {
+				t.Errorf("got status %s, want %s", status, service.DefaultStatus)
+			}
+
+			// the run is undone, there is nothing left to retry
+			time.Sleep(2 * time.Millisecond)
+			if err := run(fs, "undo"); err == nil {
+				t.Error("got no error, want no run left to undo")
+			}
+		})
+	}
+}
+
+func TestUndoKeepsEstimate(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "logged work", args: []string{"logwork", "-i", "GAIA-1", "-t", "2h", "-d", "2024-03-04"}},
+		{name: "logged work leaving the estimate", args: []string{"logwork", "-i", "GAIA-1", "-t", "2h", "-d", "2024-03-04", "--adjust-estimate", "leave"}},
+		{name: "logged work reducing the estimate by hand", args: []string{"logwork", "-i", "GAIA-1", "-t", "2h", "-d", "2024-03-04", "--adjust-estimate", "manual", "--reduce-by", "30m"}},
+		{name: "deleted worklog", args: []string{"worklog", "delete", "--id", "seeded"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := service.NewFakeJiraService(user, service.Issue{
+				Key:      "GAIA-1",
+				Summary:  "Some issue",
+				Worklogs: []service.WorklogResponseObject{worklog(user, monday, 3600)},
+			})
+			fs.SetTimeTracking("GAIA-1", service.TimeTracking{RemainingEstimate: "2h", RemainingEstimateSeconds: 7200})
+
+			if err := execute(t, fs, tt.args...); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(2 * time.Millisecond)
+			if err := run(fs, "undo"); err != nil {
+				t.Fatal(err)
+			}
+
+			tracking, err := fs.GetTimeTracking(context.Background(), "GAIA-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tracking.RemainingEstimateSeconds != 7200 {
+				t.Errorf("got a remaining estimate of %ds, want 7200s", tracking.RemainingEstimateSeconds)
+			}
+		})
+	}
+}
+
+func TestUndoVisibility(t *testing.T) {
+	srv := jiratest.NewServer()
+	defer srv.Close()
+	srv.AddIssue("GAIA-1", "Some issue")
+	seeded := srv.AddWorklog("GAIA-1", monday, 3600)
+
+	js := service.NewJiraService("token", srv.Endpoint(), srv.User().Email)
+
+	if err := execute(t, js, "worklog", "edit", "--id", seeded.Id, "--visibility", "group:developers"); err != nil {
+		t.Fatal(err)
+	}
+	if worklogs := srv.Worklogs("GAIA-1"); worklogs[0].Visibility == nil {
+		t.Fatal("the edit left the worklog visible to anyone")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if err := run(js, "undo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if worklogs := srv.Worklogs("GAIA-1"); worklogs[0].Visibility != nil {
+		t.Errorf("got the worklog restricted to %+v, want it visible to anyone", *worklogs[0].Visibility)
+	}
+}
+
+func TestUndoRetry(t *testing.T) {
+	srv := jiratest.NewServer()
+	defer srv.Close()
+	srv.AddIssue("GAIA-1", "Some issue")
+
+	js := service.NewJiraService("token", srv.Endpoint(), srv.User().Email)
+
+	if err := execute(t, js, "logwork", "-i", "GAIA-1", "-t", "1h", "--from", "2024-03-04", "--to", "2024-03-05"); err != nil {
+		t.Fatal(err)
+	}
+
+	// one of the two worklogs cannot be deleted the first time
+	srv.Fail(jiratest.Failure{Method: http.MethodDelete, Path: "/worklog", Status: http.StatusBadRequest, Times: 1})
+	time.Sleep(2 * time.Millisecond)
+	if err := run(js, "undo"); err == nil {
+		t.Fatal("got no error, want the failed delete")
+	}
+	if n := len(srv.Worklogs("GAIA-1")); n != 1 {
+		t.Fatalf("got %d worklogs after the failed undo, want 1", n)
+	}
+
+	// the worklog left is deleted by undoing the run again, and only it
+	time.Sleep(2 * time.Millisecond)
+	if err := run(js, "undo"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Worklogs("GAIA-1")); n != 0 {
+		t.Errorf("got %d worklogs, want none", n)
+	}
+	if n := count(srv.Requests(), http.MethodDelete); n != 3 {
+		t.Errorf("sent %d deletes, want 3", n)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if err := run(js, "undo"); err == nil {
+		t.Error("got no error, want no run left to undo")
+	}
+}
+
+func TestTimer(t *testing.T) {
+	srv := jiratest.NewServer()
+	defer srv.Close()
+	srv.AddIssue("GAIA-1", "Some issue")
+
+	js := service.NewJiraService("token", srv.Endpoint(), srv.User().Email)
+
+	if err := execute(t, js, "timer", "start", "GAIA-1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"timer", "pause"}, {"timer", "status"}} {
+		if err := run(js, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests := srv.Requests(); len(requests) > 0 {
+		t.Errorf("the timer sent %v to Jira before logging work", requests)
+	}
+
+	// an hour on the timer, started earlier
+	path := filepath.Join(t.TempDir(), "timer.json")
+	state, err := timer.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Timer = timer.Start("GAIA-1", "code review", time.Now().Add(-time.Hour))
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(js, "timer", "stop", "--timer-file", path); err != nil {
+		t.Fatal(err)
+	}
+
+	if worklogs := srv.Worklogs("GAIA-1"); len(worklogs) != 1 || worklogs[0].TimeSpentSeconds != 3600 {
+		t.Errorf("got worklogs %+v, want one of an hour", worklogs)
+	}
+}
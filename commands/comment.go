@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addCommentFlags adds the other ways of writing the Markdown comment -m
+// takes inline, and --visibility, to command.
+func addCommentFlags(command *cobra.Command) {
+	command.Flags().String("message-file", "", "read the comment, in Markdown, from this file, - for the standard input")
+	command.Flags().Bool("editor", false, "write the comment, in Markdown, in $VISUAL or $EDITOR")
+	command.Flags().Var(new(utils.Visibility), "visibility", "who can see the worklog, 'group:<name>' or 'role:<name>'")
+}
+
+// commentMessage returns the comment given to cmd with -m, --message-file or
+// --editor, the editor starting from draft. given is false when none of them
+// was set, the message being the default of -m then.
+func commentMessage(cmd *cobra.Command, draft string) (message string, given bool, err error) {
+	message, _ = cmd.Flags().GetString("message")
+	file, _ := cmd.Flags().GetString("message-file")
+	editor, _ := cmd.Flags().GetBool("editor")
+
+	sources := 0
+	for _, given := range []bool{cmd.Flags().Changed("message"), file != "", editor} {
+		if given {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", false, errors.New("bad flag combination, give the comment with only one of --message, --message-file or --editor")
+	}
+
+	switch {
+	case file != "":
+		message, err = readMessageFile(cmd, file)
+	case editor:
+		message, err = editMessage(draft)
+	default:
+		return message, cmd.Flags().Changed("message"), nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", false, errors.New("the comment is empty")
+	}
+
+	return message, true, nil
+}
+
+func readMessageFile(cmd *cobra.Command, file string) (string, error) {
+	if file == "-" {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		return string(data), err
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// editMessage opens draft in the user's editor and returns what was saved.
+func editMessage(draft string) (string, error) {
+	editor := utils.GetEnvString("VISUAL", utils.GetEnvString("EDITOR", "vi"))
+
+	file, err := os.CreateTemp("", "jira-cli-comment-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if draft != "" {
+		draft += "\n"
+	}
+
+	_, err = file.WriteString(draft)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// the editor may come with arguments, i.e "code --wait"
+	args := append(strings.Fields(editor), file.Name())
+	command := exec.Command(args[0], args[1:]...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("editor %q: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -1,23 +1,59 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/alinsimion/jira-cli/absence"
+	"github.com/alinsimion/jira-cli/holidays"
+	"github.com/alinsimion/jira-cli/journal"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/schedule"
 	"github.com/alinsimion/jira-cli/service"
 	"github.com/alinsimion/jira-cli/utils"
 	"github.com/spf13/cobra"
 )
 
 const (
+	dateUsage   = "i.e 12/07/2024 in the --date-order, 2024-07-12, yesterday, friday, last friday or -3d"
+	periodUsage = "can be one of 'day', 'week', 'lastweek', 'month', 'lastmonth', 'quarter', 'lastquarter' or an ISO week like 'w32' or '2024-W32'"
+
 	logworkCMD string = "logwork"
 	dumpenvCMD string = "dumpenv"
 	listCMD    string = "list"
+	absenceCMD string = "absence"
+	fillCMD    string = "fill"
+	worklogCMD string = "worklog"
+	historyCMD string = "history"
+	undoCMD    string = "undo"
+	timerCMD   string = "timer"
+	moveCMD    string = "move"
 )
 
 var (
+	// offlineCMDs never talk to Jira, or seldom and fetch the user themselves
+	// then, so they run without fetching the user first
+	offlineCMDs = map[string]bool{
+		dumpenvCMD:   true,
+		absenceCMD:   true,
+		historyCMD:   true,
+		timerCMD:     true,
+		"help":       true,
+		"completion": true,
+	}
+
+	// absenceCMDs book, share or list the time around absences, the others
+	// never read the absence file, which may then be missing or broken
+	absenceCMDs = map[string]bool{
+		logworkCMD: true,
+		fillCMD:    true,
+		listCMD:    true,
+	}
+
 //	ALL_COMMANDS = []*cobra.Command{
 //		List, LogWork, DumpEnv,
 //	}
@@ -35,36 +71,259 @@ var RootCmd = &cobra.Command{
 type CommandEngine struct {
 	RootCmd     *cobra.Command
 	AllCommands map[string]*cobra.Command
-	js          service.JiraService
+	js          service.JiraClient
+	run         *runState
+}
+
+// runState is what PersistentPreRunE sets up for the command being run.
+type runState struct {
+	// preview holds the changes kept by --dry-run until the command is done
+	preview *service.Preview
+	journal *journal.Journal
 }
 
-func NewCommandEngine(rootCmd *cobra.Command, js service.JiraService) CommandEngine {
+func NewCommandEngine(rootCmd *cobra.Command, js service.JiraClient) CommandEngine {
 	ce := CommandEngine{
 		RootCmd:     rootCmd,
 		js:          js,
 		AllCommands: map[string]*cobra.Command{},
+		run:         &runState{},
 	}
 
 	ce.AddCommands()
 
+	ce.RootCmd.PersistentFlags().Duration("connect-timeout", utils.GetEnvDuration(utils.JIRA_CONNECT_TIMEOUT, service.DefaultConnectTimeout), "how long to wait for a connection to Jira")
+	ce.RootCmd.PersistentFlags().Duration("read-timeout", utils.GetEnvDuration(utils.JIRA_READ_TIMEOUT, service.DefaultReadTimeout), "how long to wait for Jira to answer a request")
+
+	ce.RootCmd.PersistentFlags().Int("max-attempts", utils.GetEnvInt(utils.JIRA_MAX_ATTEMPTS, service.DefaultMaxAttempts), "how many times a rate limited or failed request is sent before giving up")
+
+	ce.RootCmd.PersistentFlags().Int("concurrency", utils.GetEnvInt(utils.JIRA_CONCURRENCY, service.DefaultConcurrency), "how many issues have their worklogs fetched at the same time")
+
+	ce.RootCmd.PersistentFlags().String("deployment", utils.GetEnvString(utils.JIRA_DEPLOYMENT, service.DeploymentAuto), "the kind of Jira to talk to, one of 'auto', 'cloud' or 'server' (Server and Data Center)")
+	ce.RootCmd.PersistentFlags().String("auth", utils.GetEnvString(utils.JIRA_AUTH, service.AuthAuto), "how to authenticate, one of 'auto', 'basic' (email and API token) or 'bearer' (personal access token)")
+
+	ce.RootCmd.PersistentFlags().String("record", "", "save every request to Jira and its response into cassette files in this directory")
+	ce.RootCmd.PersistentFlags().String("replay", "", "answer requests from the cassette files in this directory instead of Jira")
+
+	ce.RootCmd.PersistentFlags().Bool("debug", false, "log every request sent to Jira and how long the run spent waiting for Jira")
+
+	if err := utils.DateOrderEnum.Set(utils.GetEnvString(utils.JIRA_DATE_ORDER, utils.DateOrderDMY)); err != nil {
+		slog.Warn("could not parse date order, using default", "variable", utils.JIRA_DATE_ORDER, "default", utils.DateOrderDMY, "error", err.Error())
+	}
+	ce.RootCmd.PersistentFlags().Var(&utils.DateOrderEnum, "date-order", "the order of the day, month and year in dates like 12/07/2024, one of 'dmy', 'mdy' or 'ymd'")
+
+	ce.RootCmd.PersistentFlags().String("work-week", utils.GetEnvString(utils.JIRA_WORK_WEEK, schedule.DefaultWeek), "the days work is logged on and their hours, i.e 'sun-thu' or 'mon-thu=8,fri=4'")
+	ce.RootCmd.PersistentFlags().String("work-start", utils.GetEnvString(utils.JIRA_WORK_START, schedule.DefaultStart), "the time of day worklogs start at, i.e 09:30")
+
+	ce.RootCmd.PersistentFlags().Float64("hours-per-day", utils.GetEnvFloat(utils.JIRA_HOURS_PER_DAY, utils.DefaultUnits.HoursPerDay), "the hours in a day, 'd', of durations like 1d 2h, as set in Jira's time tracking")
+	ce.RootCmd.PersistentFlags().Float64("days-per-week", utils.GetEnvFloat(utils.JIRA_DAYS_PER_WEEK, utils.DefaultUnits.DaysPerWeek), "the days in a week, 'w', of durations like 1w 2d, as set in Jira's time tracking")
+
+	ce.RootCmd.PersistentFlags().String("holidays-country", utils.GetEnvString(utils.JIRA_HOLIDAYS_COUNTRY, ""), fmt.Sprintf("never log work on the public holidays of this country, one of %s", holidays.Countries()))
+	ce.RootCmd.PersistentFlags().String("holidays-file", utils.GetEnvString(utils.JIRA_HOLIDAYS_FILE, ""), "never log work on the holidays listed in this .ics or .json file")
+
+	ce.RootCmd.PersistentFlags().String("absence-issue", utils.GetEnvString(utils.JIRA_ABSENCE_ISSUE, ""), "book absences on this issue when logging work for a period, instead of skipping them")
+	ce.RootCmd.PersistentFlags().String("absence-file", utils.GetEnvString(utils.JIRA_ABSENCE_FILE, absence.DefaultPath()), "the file the absences are kept in")
+
+	ce.RootCmd.PersistentFlags().Bool("dry-run", false, "print the changes that would be sent to Jira instead of sending them")
+	ce.RootCmd.PersistentFlags().Bool("confirm", false, "ask before sending each change to Jira")
+
+	ce.RootCmd.PersistentFlags().String("journal-file", utils.GetEnvString(utils.JIRA_JOURNAL_FILE, journal.DefaultPath()), "the file every change sent to Jira is recorded in, for undo and history")
+
+	ce.RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cfg := NewConfig(cmd)
+		if cfg.Debug {
+			slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		// durations read from here on, the work week included, go by these
+		utils.WorkUnits.HoursPerDay, _ = cmd.Flags().GetFloat64("hours-per-day")
+		utils.WorkUnits.DaysPerWeek, _ = cmd.Flags().GetFloat64("days-per-week")
+		if err := utils.WorkUnits.Validate(); err != nil {
+			return err
+		}
+
+		workWeek, err := workSchedule(cmd)
+		if err != nil {
+			return err
+		}
+		cfg.Schedule = workWeek
+
+		calendar, err := holidayCalendar(cmd)
+		if err != nil {
+			return err
+		}
+		cfg.Holidays = calendar
+
+		if absenceCMDs[cmd.Name()] {
+			store, err := absenceStore(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.Absences = store
+			cfg.AbsenceIssue, _ = cmd.Flags().GetString("absence-issue")
+		}
+
+		ce.run.preview = &service.Preview{In: cmd.InOrStdin()}
+		ce.run.preview.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		ce.run.preview.Confirm, _ = cmd.Flags().GetBool("confirm")
+		cfg.Preview = ce.run.preview
+
+		journalFile, _ := cmd.Flags().GetString("journal-file")
+		ce.run.journal = journal.New(journalFile, strings.Join(os.Args[1:], " "))
+		cfg.Journal = ce.run.journal
+
+		if configurable, ok := ce.js.(service.Configurable); ok {
+			configurable.Configure(cfg)
+		}
+
+		if offlineCMDs[cmd.Name()] || cmd.HasParent() && offlineCMDs[cmd.Parent().Name()] {
+			return nil
+		}
+
+		return ce.js.GetMySelf(cmd.Context())
+	}
+
 	ce.AllCommands[logworkCMD].Flags().StringP("issueKey", "i", "", "issue key to log work for")
 	// ce.AllCommands[logworkCMD].MarkFlagRequired("issueKey")
-	ce.AllCommands[logworkCMD].Flags().StringP("date", "d", utils.TODAY_FLAG, "the date to log the work on in the format dd/mm/yyyy")
-	ce.AllCommands[logworkCMD].Flags().StringP("message", "m", "I did some work here", "the comment on the work log")
-	ce.AllCommands[logworkCMD].Flags().Var(&utils.PeriodEnum, "period", "can be one of 'month', 'week', 'lastweek' or 'lastmonth'")
-	ce.AllCommands[logworkCMD].Flags().Float32P("time", "t", utils.DEFAULT_LOG_TIME, "specifies the amount of hours to log. Can be float as well, i.e 2.5")
+	ce.AllCommands[logworkCMD].Flags().StringP("date", "d", utils.TODAY_FLAG, "the date to log the work on, "+dateUsage)
+	ce.AllCommands[logworkCMD].Flags().StringP("message", "m", "I did some work here", "the comment on the work log, in Markdown")
+	addCommentFlags(ce.AllCommands[logworkCMD])
+	ce.AllCommands[logworkCMD].Flags().VarP(new(period.Period), "period", "p", periodUsage)
+	ce.AllCommands[logworkCMD].Flags().String("from", "", "the first date to log work on, "+dateUsage)
+	ce.AllCommands[logworkCMD].Flags().String("to", "", "the last date to log work on, "+dateUsage+", defaults to today")
+	mode := utils.LogMode(utils.LogModeAdd)
+	ce.AllCommands[logworkCMD].Flags().Var(&mode, "mode", "what to do on days with work logged already, one of 'add' (log the time anyway), 'topup' (log what is missing to reach the time) or 'skip-logged' (leave those days alone)")
+	ce.AllCommands[logworkCMD].Flags().StringP("time", "t", utils.DEFAULT_LOG_TIME, "the time to log, in hours like 2.5 or in Jira's notation like 1h 30m, 90m or 1d. Defaults to the time --work-week gives the day, 6h without")
+	ce.AllCommands[logworkCMD].Flags().Bool("allow-weekend", false, "log work on the days off of --work-week too")
+	adjustEstimate := utils.AdjustEstimate(utils.AdjustEstimateAuto)
+	ce.AllCommands[logworkCMD].Flags().Var(&adjustEstimate, "adjust-estimate", "what to do with the remaining estimate of the issue, one of 'auto' (reduce it by the time logged), 'leave' (keep it), 'new' (set it to --new-estimate) or 'manual' (reduce it by --reduce-by)")
+	ce.AllCommands[logworkCMD].Flags().String("new-estimate", "", "the new remaining estimate with --adjust-estimate new, i.e 2d or 4h 30m")
+	ce.AllCommands[logworkCMD].Flags().String("reduce-by", "", "how much to reduce the remaining estimate by with --adjust-estimate manual, i.e 1h")
+	ce.AllCommands[logworkCMD].Flags().Bool("strict", false, "refuse to log work going over the remaining estimate of the issue instead of warning about it")
 
 	ce.AllCommands[listCMD].Flags().Var(&utils.ListableEnum, "object", "can be one of 'issues' or 'worklogs'")
 	ce.AllCommands[listCMD].Flags().IntP("month", "m", -1, "the month to report worklog for")
 	ce.AllCommands[listCMD].Flags().IntP("year", "y", -1, "the year to report worklog for")
+	ce.AllCommands[listCMD].Flags().VarP(new(period.Period), "period", "p", periodUsage)
+	ce.AllCommands[listCMD].Flags().String("from", "", "the first date to report worklog for, "+dateUsage)
+	ce.AllCommands[listCMD].Flags().String("to", "", "the last date to report worklog for, "+dateUsage+", defaults to today")
 
 	return ce
 }
 
-func (ce *CommandEngine) Execute(cmd *cobra.Command) {
-	if err := cmd.Execute(); err != nil {
-		slog.Error("Oops. An error while executing jira-cli", "error", err.Error())
-		os.Exit(1)
+// listRange picks the days list reports on: a --period, --from and --to,
+// or else the month given with -m and -y, the current one by default.
+func listRange(cmd *cobra.Command) (period.Range, error) {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+
+	selected := utils.GetPeriodFlag(cmd)
+
+	if selected != "" || from != "" || to != "" {
+		return utils.RangeFromFlags(selected, from, to, time.Now())
+	}
+
+	month, _ := cmd.Flags().GetInt("month")
+	year, _ := cmd.Flags().GetInt("year")
+
+	var currentMonth time.Month
+	var currentYear int
+
+	if month == -1 {
+		currentMonth = time.Now().Month()
+	} else {
+		currentMonth = time.Month(month)
+	}
+
+	if year == -1 {
+		currentYear = time.Now().Year()
+	} else {
+		currentYear = year
+	}
+
+	return period.Month(currentYear, currentMonth, time.Local), nil
+}
+
+// workSchedule reads the working week given with --work-week and --work-start.
+func workSchedule(cmd *cobra.Command) (*schedule.Schedule, error) {
+	week, _ := cmd.Flags().GetString("work-week")
+	start, _ := cmd.Flags().GetString("work-start")
+
+	return schedule.Parse(week, start)
+}
+
+// holidayCalendar loads the holidays selected by --holidays-country and --holidays-file.
+func holidayCalendar(cmd *cobra.Command) (*holidays.Calendar, error) {
+	country, _ := cmd.Flags().GetString("holidays-country")
+	file, _ := cmd.Flags().GetString("holidays-file")
+
+	return holidays.Load(country, file)
+}
+
+// absenceStore opens the absences kept in --absence-file.
+func absenceStore(cmd *cobra.Command) (*absence.Store, error) {
+	path, _ := cmd.Flags().GetString("absence-file")
+
+	return absence.Open(path)
+}
+
+// NewConfig builds the service configuration from the global flags.
+func NewConfig(cmd *cobra.Command) service.Config {
+	cfg := service.DefaultConfig()
+	cfg.ConnectTimeout, _ = cmd.Flags().GetDuration("connect-timeout")
+	cfg.ReadTimeout, _ = cmd.Flags().GetDuration("read-timeout")
+	cfg.MaxAttempts, _ = cmd.Flags().GetInt("max-attempts")
+	cfg.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	cfg.Deployment, _ = cmd.Flags().GetString("deployment")
+	cfg.Auth, _ = cmd.Flags().GetString("auth")
+	cfg.RecordDir, _ = cmd.Flags().GetString("record")
+	cfg.ReplayDir, _ = cmd.Flags().GetString("replay")
+	cfg.Debug, _ = cmd.Flags().GetBool("debug")
+
+	return cfg
+}
+
+// drawHeld prints the changes a dry run kept from Jira.
+func (ce *CommandEngine) drawHeld() {
+	if ce.run.preview == nil || !ce.run.preview.DryRun {
+		return
+	}
+
+	changes := ce.run.preview.Changes()
+	if len(changes) == 0 {
+		fmt.Println("Dry run, there was nothing to send to Jira")
+		return
+	}
+
+	fmt.Printf("Dry run, %d change(s) were not sent to Jira:\n", len(changes))
+	service.DrawChanges(changes)
+}
+
+func (ce *CommandEngine) Execute(ctx context.Context, cmd *cobra.Command) {
+	start := time.Now()
+	err := cmd.ExecuteContext(ctx)
+
+	// cobra skips the post run hooks of a failed command, the changes a dry
+	// run held until then are worth seeing all the more
+	ce.drawHeld()
+
+	if debug, _ := ce.RootCmd.PersistentFlags().GetBool("debug"); debug {
+		calls, spent := service.Usage()
+		slog.Debug("jira api usage", "calls", calls, "spent", spent, "elapsed", time.Since(start))
+	}
+
+	if err != nil {
+		hint, code := describeError(err)
+		if hint != "" {
+			slog.Error("Oops. An error while executing jira-cli", "error", err.Error(), "hint", hint)
+		} else {
+			slog.Error("Oops. An error while executing jira-cli", "error", err.Error())
+		}
+		os.Exit(code)
 	}
 }
 
@@ -83,32 +342,22 @@ func (ce CommandEngine) AddCommands() error {
 		Use:   listCMD,
 		Short: "lists your issues",
 		Example: `list --object issues # list all the user's [In Progress] issues
-list --object worklogs # list all the user's [In Progress] issues`,
+list --object worklogs # list all the user's worklogs of the current month
+list --object worklogs --period lastweek # list all the user's worklogs of last week
+list --object worklogs --from 01/07/2024 --to 30/09/2024 # list all the user's worklogs of the third quarter of 2024`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			month, _ := cmd.Flags().GetInt("month")
-			year, _ := cmd.Flags().GetInt("year")
-
-			var date time.Time
-			var currentMonth time.Month
-			var currentYear int
-
-			if month == -1 {
-				currentMonth = time.Now().Month()
-			} else {
-				currentMonth = time.Month(month)
-			}
-
-			if year == -1 {
-				currentYear = time.Now().Year()
-			} else {
-				currentYear = year
+			days, err := listRange(cmd)
+			if err != nil {
+				return err
 			}
 
-			date = time.Date(currentYear, currentMonth, 1, 0, 0, 0, 0, time.Local)
-
 			if utils.ListableEnum == utils.Listable(utils.ListableIssues) {
-				issues, _ := ce.js.GetUsersIssuesFromPeriod(date, time.Now())
+				issues, err := ce.js.GetUsersIssuesFromPeriod(cmd.Context(), days.From, days.To)
+				if err != nil {
+					return err
+				}
+
 				table := map[string]map[string][]string{}
 				for _, issue := range issues {
 					table[issue.Key] = map[string][]string{
@@ -120,15 +369,40 @@ list --object worklogs # list all the user's [In Progress] issues`,
 				utils.DrawTable(table)
 
 			} else if utils.ListableEnum == utils.Listable(utils.ListableWorklogs) {
-				table, err := ce.js.GetUserWorkLogs(date)
+				table, err := ce.js.GetUserWorkLogs(cmd.Context(), days)
 
-				if err != nil {
+				// some issues failing still leaves the others worth showing
+				if err != nil && len(table) == 0 {
 					return err
 				}
 
-				fmt.Printf("Listing issue worklogs for Month %s, %d\n", date.Month(), date.Year())
+				if days == period.Month(days.From.Year(), days.From.Month(), time.Local) {
+					fmt.Printf("Listing issue worklogs for Month %s, %d\n", days.From.Month(), days.From.Year())
+				} else {
+					fmt.Printf("Listing issue worklogs for %s\n", days)
+				}
 
-				utils.DrawTable(table)
+				utils.DrawTable(table, service.TotalRow, service.AbsencesRow, service.HolidaysRow)
+
+				if calendar, calendarErr := holidayCalendar(cmd); calendarErr == nil {
+					for _, date := range days.Dates() {
+						if name, ok := calendar.Holiday(date); ok {
+							fmt.Printf("%s %s: %s\n", service.HolidayMark, date.Format(time.DateOnly), name)
+						}
+					}
+				}
+
+				if store, storeErr := absenceStore(cmd); storeErr == nil {
+					for _, away := range store.Absences {
+						if away.During(days) {
+							fmt.Printf("%s %s\n", away.Kind.Mark(), away)
+						}
+					}
+				}
+
+				if err != nil {
+					return err
+				}
 
 			} else {
 				return fmt.Errorf("Bad flag for object")
@@ -147,24 +421,42 @@ list --object worklogs # list all the user's [In Progress] issues`,
 		Example: fmt.Sprintf(`%[1]s -t 6 -i GAIA-1232 -d 12/07/2024
 %[1]s -t 6 -i GAIA-1232 	    			# this will log work today
 %[1]s -t 6 -i GAIA-1232 --period week  	# this will log work for the week in progress until today
-%[1]s -t 6 -i GAIA-1232 --period month  	# this will log work for the month in progress until today`, logworkCMD),
+%[1]s -t 6 -i GAIA-1232 --period month  	# this will log work for the month in progress until today
+%[1]s -t 6 -i GAIA-1232 --period w32  	# this will log work for the ISO week 32 of this year
+%[1]s -t 6 -i GAIA-1232 --from 01/07/2024 --to 12/07/2024  	# this will log work from the 1st to the 12th of July 2024
+%[1]s -t 8 -i GAIA-1232 --period month --mode topup  	# this will log what is missing to reach 8h on every day of the month
+%[1]s -t 2 -i GAIA-1232 --adjust-estimate new --new-estimate 1d  	# this will log 2h and set the remaining estimate to 1d
+%[1]s -t 2 -i GAIA-1232 --message-file notes.md --visibility group:developers  	# this will log 2h with the Markdown of notes.md, seen by developers only`, logworkCMD),
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			lgParams := utils.NewLogWorkParams(cmd)
+			lgParams, err := utils.NewLogWorkParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := lgParams.Validate(); err != nil {
+				return err
+			}
 
-			err := lgParams.Validate()
+			lgParams.Message, _, err = commentMessage(cmd, "")
 			if err != nil {
-				slog.Error("bad flag combination", "error", err.Error())
-				os.Exit(1)
+				return err
 			}
 
-			return ce.js.LogWorkMulti(lgParams)
+			return ce.js.LogWorkMulti(cmd.Context(), lgParams)
 
 		},
 	}
 
 	ce.RootCmd.AddCommand(LogWork)
 
+	ce.addAbsenceCommands()
+	ce.addFillCommand()
+	ce.addWorklogCommands()
+	ce.addJournalCommands()
+	ce.addTimerCommands()
+	ce.addMoveCommand()
+
 	ce.AllCommands[dumpenvCMD] = DumpEnv
 	ce.AllCommands[listCMD] = List
 	ce.AllCommands[logworkCMD] = LogWork
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alinsimion/jira-cli/service"
+)
+
+// Exit codes jira-cli terminates with, so scripts can tell failures apart.
+const (
+	ExitError        = 1
+	ExitUnauthorized = 3
+	ExitForbidden    = 4
+	ExitNotFound     = 5
+	ExitRateLimited  = 6
+	ExitInterrupted  = 130
+)
+
+// describeError turns an error returned by a command into a hint for the
+// user and the exit code to terminate with.
+func describeError(err error) (string, int) {
+	switch {
+	case errors.Is(err, service.ErrUnauthorized):
+		return "Jira rejected your credentials, check JIRA_USER_EMAIL and JIRA_API_KEY", ExitUnauthorized
+	case errors.Is(err, service.ErrForbidden):
+		return "you do not have permission to do this in Jira", ExitForbidden
+	case errors.Is(err, service.ErrNotFound):
+		return "Jira could not find what you asked for, check the issue key", ExitNotFound
+	case errors.Is(err, service.ErrRateLimited):
+		return "Jira is rate limiting you, try again in a few minutes", ExitRateLimited
+	case errors.Is(err, context.Canceled):
+		return "interrupted", ExitInterrupted
+	default:
+		return "", ExitError
+	}
+}
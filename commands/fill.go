@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/service"
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addFillCommand adds the fill command, which logs the hours missing from
+// the user's timesheet on a set of issues.
+func (ce CommandEngine) addFillCommand() {
+	var Fill = &cobra.Command{
+		Use:   fillCMD,
+		Short: "logs the hours missing to reach a daily target",
+		Long: `logs, on every working day of a period, the hours missing to reach a daily target,
+shared between issues equally, by weight, or like the work already logged that week`,
+		Example: `fill -p lastweek --target 8                        # shares the missing hours like the work logged last week
+fill -p lastweek --target 8 --issues GAIA-1232,GAIA-7  # shares them equally between two issues
+fill -p month --weights GAIA-1232=3,GAIA-7=1           # logs three quarters of them on GAIA-1232
+fill --from 01/07/2024 --to 12/07/2024 --yes           # posts without asking`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := utils.NewFillParams(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := params.Validate(); err != nil {
+				return err
+			}
+
+			days, err := utils.RangeFromFlags(params.Period, params.From, params.To, time.Now())
+			if err != nil {
+				return err
+			}
+
+			plan, planErr := ce.js.PlanFill(cmd.Context(), params)
+			if planErr != nil && len(plan) == 0 {
+				return planErr
+			}
+
+			target := fmt.Sprintf("%s a day", utils.FormatDuration(params.Target))
+			if params.ScheduledTarget {
+				target = fmt.Sprintf("the time --work-week gives each day, %s by default", utils.FormatDuration(params.Target))
+			}
+
+			if len(plan) == 0 {
+				fmt.Printf("Nothing to fill, every working day of %s is logged up to %s\n", days, target)
+				return nil
+			}
+
+			fmt.Printf("Worklogs to fill %s up to %s\n", days, target)
+			utils.DrawTable(service.PlanTable(plan, days))
+
+			if planErr != nil {
+				fmt.Println(planErr)
+			}
+
+			// --dry-run and --confirm decide on each worklog themselves
+			yes, _ := cmd.Flags().GetBool("yes")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			confirm, _ := cmd.Flags().GetBool("confirm")
+
+			if !yes && !dryRun && !confirm {
+				fmt.Printf("Post %d worklog(s)? [y/N] ", len(plan))
+
+				answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if !slices.Contains([]string{"y", "yes"}, strings.ToLower(strings.TrimSpace(answer))) {
+					fmt.Println("Nothing was logged")
+					return nil
+				}
+			}
+
+			var errs []error
+			posted := 0
+			for _, params := range plan {
+				if cmd.Context().Err() != nil {
+					break
+				}
+
+				if err := ce.js.LogWork(cmd.Context(), params); err != nil {
+					errs = append(errs, fmt.Errorf("%s on %s: %w", params.IssueKey, params.Date, err))
+					continue
+				}
+				posted++
+			}
+
+			if err := cmd.Context().Err(); err != nil {
+				fmt.Printf("Interrupted: %d of %d worklog(s) were posted\n", posted, len(plan))
+				return err
+			}
+
+			return errors.Join(errs...)
+		},
+	}
+
+	Fill.Flags().VarP(new(period.Period), "period", "p", periodUsage)
+	Fill.Flags().String("from", "", "the first date to fill, "+dateUsage)
+	Fill.Flags().String("to", "", "the last date to fill, "+dateUsage+", defaults to today")
+	Fill.Flags().String("target", utils.DEFAULT_LOG_TIME, "the time every working day should have logged, i.e 8 or 7h 30m, defaults to the time --work-week gives the day, 6h without")
+	Fill.Flags().StringSlice("issues", nil, "issues to share the missing hours between equally, i.e GAIA-1232,GAIA-7")
+	Fill.Flags().StringToString("weights", nil, "issues to share the missing hours between by weight, i.e GAIA-1232=3,GAIA-7=1")
+	Fill.Flags().StringP("message", "m", "I did some work here", "the comment on the work logs")
+	Fill.Flags().BoolP("yes", "y", false, "post the worklogs without asking")
+
+	ce.RootCmd.AddCommand(Fill)
+
+	ce.AllCommands[fillCMD] = Fill
+}
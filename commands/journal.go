@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/alinsimion/jira-cli/journal"
+	"github.com/alinsimion/jira-cli/service"
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addJournalCommands adds the history and undo commands, built on the
+// journal of the changes sent to Jira.
+func (ce CommandEngine) addJournalCommands() {
+	var History = &cobra.Command{
+		Use:   historyCMD,
+		Short: "lists the runs that changed something in Jira",
+		Example: `history            # the last 20 runs
+history --run 20240712-101502.123   # the changes of one run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runs, err := readJournal(cmd)
+			if err != nil {
+				return err
+			}
+
+			if id, _ := cmd.Flags().GetString("run"); id != "" {
+				run, ok := journal.FindRun(runs, id)
+				if !ok {
+					return fmt.Errorf("no run %s in the journal", id)
+				}
+
+				drawEntries(run)
+				return nil
+			}
+
+			limit, _ := cmd.Flags().GetInt("limit")
+			if limit > 0 && len(runs) > limit {
+				runs = runs[len(runs)-limit:]
+			}
+
+			rows := [][]string{}
+			for _, run := range runs {
+				status := ""
+				switch {
+				case run.RevertedBy != "":
+					status = "undone by " + run.RevertedBy
+				case run.Reverted():
+					status = "partly undone, undo it again for the rest"
+				case run.Reverts != "":
+					status = "undoes " + run.Reverts
+				}
+
+				rows = append(rows, []string{run.Id, run.Time.Local().Format(time.DateTime), run.Command, strconv.Itoa(len(run.Entries)), status})
+			}
+
+			utils.DrawRows([]string{"Run", "Time", "Command", "Changes", "Status"}, rows)
+
+			return nil
+		},
+	}
+
+	var Undo = &cobra.Command{
+		Use:   undoCMD,
+		Short: "reverts the changes of the last run, or of a chosen one",
+		Example: `undo                               # reverts the last run that was not undone yet
+undo --run 20240712-101502.123     # reverts a run listed by history
+undo --dry-run                     # shows what would be reverted`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runs, err := readJournal(cmd)
+			if err != nil {
+				return err
+			}
+
+			var run *journal.Run
+			var ok bool
+			if id, _ := cmd.Flags().GetString("run"); id != "" {
+				run, ok = journal.FindRun(runs, id)
+				if !ok {
+					return fmt.Errorf("no run %s in the journal", id)
+				}
+				if run.RevertedBy != "" {
+					return fmt.Errorf("run %s was undone already by %s", id, run.RevertedBy)
+				}
+			} else {
+				run, ok = journal.LastRun(runs)
+				if !ok {
+					return errors.New("there is no run left to undo")
+				}
+			}
+
+			if run.Reverted() {
+				fmt.Printf("Undoing the rest of run %s: %s\n", run.Id, run.Command)
+			} else {
+				fmt.Printf("Undoing run %s: %s\n", run.Id, run.Command)
+			}
+
+			ce.run.journal.Reverts = run.Id
+
+			return ce.revert(cmd.Context(), run)
+		},
+	}
+
+	History.Flags().String("run", "", "the run to list the changes of")
+	History.Flags().IntP("limit", "n", 20, "how many of the last runs to list, 0 for all")
+
+	Undo.Flags().String("run", "", "the run to revert, defaults to the last one")
+
+	ce.RootCmd.AddCommand(History, Undo)
+
+	ce.AllCommands[historyCMD] = History
+	ce.AllCommands[undoCMD] = Undo
+}
+
+func readJournal(cmd *cobra.Command) ([]*journal.Run, error) {
+	path, _ := cmd.Flags().GetString("journal-file")
+
+	return journal.Read(path)
+}
+
+// revert undoes the changes of run not reverted yet, the last one first,
+// journaling each revert against the entry it reverts so that the ones
+// failing are left for a later undo to retry: created worklogs
+// are deleted, edited ones get their former values back, deleted ones are
+// logged again, under a new id, and moved issues go back to the status they
+// were in. Worklogs are deleted and logged again with the adjustment that
+// gives the remaining estimate back what the change did to it.
+func (ce CommandEngine) revert(ctx context.Context, run *journal.Run) error {
+	var errs []error
+
+	for i := len(run.Entries) - 1; i >= 0; i-- {
+		entry := run.Entries[i]
+		if entry.RevertedBy != "" {
+			continue
+		}
+
+		ce.run.journal.RevertsEntry = i + 1
+		held, recorded := ce.run.preview.Held(), ce.run.journal.Recorded()
+
+		var err error
+		switch {
+		case entry.Action == service.ActionLogWork:
+			err = ce.js.DeleteWorklog(ctx, entry.Issue, entry.Worklog, deleteAdjustment(entry))
+		case entry.Action == service.ActionTransition:
+			if entry.FromStatus == "" {
+				err = errors.New("the status was not recorded before the change")
+				break
+			}
+			err = ce.js.UpdateIssue(ctx, entry.Issue, entry.FromStatus)
+		case entry.Before == nil:
+			err = errors.New("the worklog was not recorded before the change")
+		case entry.Action == service.ActionUpdateWorklog:
+			visibility := entry.Before.Visibility
+			if visibility.IsZero() && entry.After != nil && !entry.After.Visibility.IsZero() {
+				// the worklog was visible to anyone before it was restricted
+				visibility = &utils.Visibility{}
+			}
+			err = ce.js.UpdateWorklog(ctx, entry.Issue, entry.Worklog, service.WorklogUpdate{
+				Started:          entry.Before.Started,
+				TimeSpentSeconds: entry.Before.TimeSpentSeconds,
+				Comment:          &entry.Before.Comment,
+				Visibility:       visibility,
+			})
+		case entry.Action == service.ActionDeleteWorklog:
+			params := utils.LogWorkParams{
+				Date:           utils.TODAY_FLAG,
+				IssueKey:       entry.Issue,
+				TimeSpent:      time.Duration(entry.Before.TimeSpentSeconds) * time.Second,
+				Message:        entry.Before.Comment,
+				Started:        entry.Before.Started,
+				AdjustEstimate: utils.AdjustEstimate(entry.AdjustEstimate),
+			}
+			if entry.AdjustEstimate == utils.AdjustEstimateManual {
+				params.ReduceBy = time.Duration(entry.AdjustBySeconds) * time.Second
+			}
+			if !entry.Before.Visibility.IsZero() {
+				params.Visibility = *entry.Before.Visibility
+			}
+			err = ce.js.LogWork(ctx, params)
+		default:
+			err = errors.New("cannot be undone")
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s %s %s: %w", entry.Action, entry.Issue, entry.Worklog, err))
+			continue
+		}
+
+		// the issue was back in its status already, there was nothing to send
+		if entry.Action == service.ActionTransition && ce.run.preview.Held() == held && ce.run.journal.Recorded() == recorded {
+			err := ce.run.journal.Record(journal.Entry{Action: entry.Action, Issue: entry.Issue, FromStatus: entry.FromStatus, ToStatus: entry.FromStatus})
+			if err != nil {
+				slog.Warn("could not write the change to the journal", "action", entry.Action, "issue", entry.Issue, "error", err.Error())
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteAdjustment gives back to the remaining estimate, as the worklog
+// logged by entry is deleted, what logging it took off. An estimate that was
+// set anew is left as it is, what it was before is not known.
+func deleteAdjustment(entry journal.Entry) service.DeleteAdjustment {
+	switch entry.AdjustEstimate {
+	case utils.AdjustEstimateLeave:
+		return service.DeleteAdjustment{AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateLeave)}
+	case utils.AdjustEstimateManual:
+		return service.DeleteAdjustment{
+			AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateManual),
+			IncreaseBy:     time.Duration(entry.AdjustBySeconds) * time.Second,
+		}
+	case utils.AdjustEstimateNew:
+		slog.Warn("the worklog set a new remaining estimate, undo leaves it as it is", "issue", entry.Issue, "worklog", entry.Worklog)
+		return service.DeleteAdjustment{AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateLeave)}
+	default:
+		return service.DeleteAdjustment{}
+	}
+}
+
+func drawEntries(run *journal.Run) {
+	rows := [][]string{}
+	for _, entry := range run.Entries {
+		worklog := entry.After
+		if worklog == nil {
+			worklog = entry.Before
+		}
+
+		started, duration, comment := "", "", ""
+		if worklog != nil {
+			if !worklog.Started.IsZero() {
+				started = worklog.Started.Local().Format("2006-01-02 15:04")
+			}
+			if worklog.TimeSpentSeconds > 0 {
+				duration = utils.FormatDuration(time.Duration(worklog.TimeSpentSeconds) * time.Second)
+			}
+			comment = worklog.Comment
+		}
+		if entry.Action == service.ActionTransition {
+			comment = entry.FromStatus + " to " + entry.ToStatus
+		}
+
+		rows = append(rows, []string{entry.Action, entry.Issue, entry.Worklog, started, duration, comment})
+	}
+
+	fmt.Printf("Run %s: %s\n", run.Id, run.Command)
+	utils.DrawRows([]string{"Action", "Issue", "Worklog", "Started", "Duration", "Comment"}, rows)
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addMoveCommand adds the move command, which moves an issue to another
+// status through its workflow.
+func (ce CommandEngine) addMoveCommand() {
+	var Move = &cobra.Command{
+		Use:   moveCMD + " ISSUE STATUS",
+		Short: "moves an issue to another status",
+		Example: `move GAIA-1232 "In Progress"
+move GAIA-1232 done          # the status is matched in any case
+move GAIA-1232 done --dry-run`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// statuses with spaces may be given unquoted
+			return ce.js.UpdateIssue(cmd.Context(), args[0], strings.Join(args[1:], " "))
+		},
+	}
+
+	ce.RootCmd.AddCommand(Move)
+
+	ce.AllCommands[moveCMD] = Move
+}
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alinsimion/jira-cli/timer"
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addTimerCommands adds the timer command, which tracks the time spent on an
+// issue and logs it when the timer is stopped.
+func (ce CommandEngine) addTimerCommands() {
+	var Timer = &cobra.Command{
+		Use:   timerCMD,
+		Short: "tracks the time spent on an issue and logs it when stopped",
+		Long: `tracks the time spent on an issue, across shells, and logs it when the timer is stopped,
+starting when the timer was started and rounded with --round and --rounding`,
+	}
+
+	var Start = &cobra.Command{
+		Use:   "start ISSUE",
+		Short: "starts a timer on an issue",
+		Example: `timer start GAIA-1232
+timer start GAIA-1232 -m "code review"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := timerState(cmd)
+			if err != nil {
+				return err
+			}
+
+			if state.Timer != nil {
+				return fmt.Errorf("a timer is on already: %s, stop it or switch to %s", state.Timer, args[0])
+			}
+
+			return startTimer(cmd, state, args[0], time.Now())
+		},
+	}
+
+	var Stop = &cobra.Command{
+		Use:   "stop",
+		Short: "stops the timer and logs the time tracked",
+		Example: `timer stop
+timer stop -m "fixed the login page"   # logs with another comment than the one given on start`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := timerState(cmd)
+			if err != nil {
+				return err
+			}
+
+			message, _ := cmd.Flags().GetString("message")
+
+			_, err = ce.stopTimer(cmd, state, message, time.Now())
+			return err
+		},
+	}
+
+	var Switch = &cobra.Command{
+		Use:     "switch ISSUE",
+		Short:   "stops the timer, logs the time tracked and starts a timer on another issue",
+		Example: `timer switch GAIA-7 -m "standup"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := timerState(cmd)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+
+			if state.Timer != nil {
+				// --message is the comment of the new timer, not of the stopped one
+				stopped, err := ce.stopTimer(cmd, state, "", now)
+				if err != nil || !stopped {
+					return err
+				}
+			}
+
+			return startTimer(cmd, state, args[0], now)
+		},
+	}
+
+	var Status = &cobra.Command{
+		Use:   "status",
+		Short: "shows the timer and the time tracked so far",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := timerState(cmd)
+			if err != nil {
+				return err
+			}
+
+			if state.Timer == nil {
+				fmt.Println("No timer is on")
+				return nil
+			}
+
+			tracked := state.Timer.Tracked(time.Now())
+			fmt.Printf("Timer on %s: %s tracked, %s to log\n", state.Timer, tracked.Truncate(time.Second), utils.FormatDuration(timerRounding(cmd).Round(tracked, timerStep(cmd))))
+
+			return nil
+		},
+	}
+
+	var Pause = &cobra.Command{
+		Use:   "pause",
+		Short: "pauses the timer, the time until it is resumed is not tracked",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return changeTimer(cmd, "paused", (*timer.Timer).Pause)
+		},
+	}
+
+	var Resume = &cobra.Command{
+		Use:   "resume",
+		Short: "resumes a paused timer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return changeTimer(cmd, "resumed", (*timer.Timer).Resume)
+		},
+	}
+
+	rounding := timer.Rounding(timer.RoundingUp)
+	if err := rounding.Set(utils.GetEnvString(utils.JIRA_TIMER_ROUNDING, timer.RoundingUp)); err != nil {
+		slog.Warn("could not parse rounding, using default", "variable", utils.JIRA_TIMER_ROUNDING, "default", timer.RoundingUp, "error", err.Error())
+	}
+
+	Timer.PersistentFlags().String("timer-file", utils.GetEnvString(utils.JIRA_TIMER_FILE, timer.DefaultPath()), "the file the timer is kept in")
+	Timer.PersistentFlags().Duration("round", utils.GetEnvDuration(utils.JIRA_TIMER_ROUND, time.Minute), "the time tracked is logged in multiples of this, i.e 15m")
+	Timer.PersistentFlags().Var(&rounding, "rounding", "how the time tracked is rounded to --round, one of 'up', 'nearest' or 'down'")
+
+	for _, command := range []*cobra.Command{Start, Switch} {
+		command.Flags().StringP("message", "m", "I did some work here", "the comment on the work log")
+	}
+	Stop.Flags().StringP("message", "m", "", "the comment on the work log, defaults to the one given on start")
+
+	Timer.AddCommand(Start, Stop, Switch, Status, Pause, Resume)
+	ce.RootCmd.AddCommand(Timer)
+
+	ce.AllCommands[timerCMD] = Timer
+}
+
+// timerState opens the timer kept in --timer-file.
+func timerState(cmd *cobra.Command) (*timer.State, error) {
+	path, _ := cmd.Flags().GetString("timer-file")
+
+	return timer.Open(path)
+}
+
+func timerStep(cmd *cobra.Command) time.Duration {
+	step, _ := cmd.Flags().GetDuration("round")
+
+	return step
+}
+
+func timerRounding(cmd *cobra.Command) timer.Rounding {
+	return timer.Rounding(cmd.Flags().Lookup("rounding").Value.String())
+}
+
+func startTimer(cmd *cobra.Command, state *timer.State, issue string, now time.Time) error {
+	comment, _ := cmd.Flags().GetString("message")
+
+	state.Timer = timer.Start(issue, comment, now)
+	if err := state.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Timer started on %s at %s\n", issue, now.Format("15:04"))
+
+	return nil
+}
+
+// changeTimer applies change, a pause or a resume, to the timer and saves it.
+func changeTimer(cmd *cobra.Command, done string, change func(*timer.Timer, time.Time) error) error {
+	state, err := timerState(cmd)
+	if err != nil {
+		return err
+	}
+
+	if state.Timer == nil {
+		return errors.New("no timer is on")
+	}
+
+	now := time.Now()
+	if err := change(state.Timer, now); err != nil {
+		return err
+	}
+
+	if err := state.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Timer on %s %s, %s tracked\n", state.Timer.Issue, done, state.Timer.Tracked(now).Truncate(time.Second))
+
+	return nil
+}
+
+// stopTimer logs the time tracked by the timer of state, rounded, as a
+// worklog starting when the timer was started, then forgets the timer. The
+// comment is message, or the one the timer was started with when empty. It
+// reports false and keeps the timer when the worklog was held back by
+// --dry-run or --confirm.
+func (ce CommandEngine) stopTimer(cmd *cobra.Command, state *timer.State, message string, now time.Time) (bool, error) {
+	t := state.Timer
+	if t == nil {
+		return false, errors.New("no timer is on")
+	}
+
+	tracked := t.Tracked(now)
+	logged := timerRounding(cmd).Round(tracked, timerStep(cmd))
+
+	if message == "" {
+		message = t.Comment
+	}
+
+	if logged < time.Minute {
+		fmt.Printf("Timer on %s stopped, %s tracked is too little to log\n", t.Issue, tracked.Truncate(time.Second))
+	} else {
+		held := ce.run.preview.Held()
+
+		// the timer runs offline, Jira is only asked for the user once there is work to log
+		if err := ce.js.GetMySelf(cmd.Context()); err != nil {
+			return false, err
+		}
+
+		err := ce.js.LogWork(cmd.Context(), utils.LogWorkParams{
+			Date:      utils.TODAY_FLAG,
+			IssueKey:  t.Issue,
+			TimeSpent: logged,
+			Message:   message,
+			Started:   t.Started,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if ce.run.preview.Held() > held {
+			fmt.Printf("Nothing was logged, the timer on %s is kept\n", t.Issue)
+			return false, nil
+		}
+
+		fmt.Printf("Timer on %s stopped, %s tracked, %s logged\n", t.Issue, tracked.Truncate(time.Second), utils.FormatDuration(logged))
+	}
+
+	state.Timer = nil
+
+	return true, state.Save()
+}
@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/service"
+	"github.com/alinsimion/jira-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// addWorklogCommands adds the worklog command, which fixes or removes
+// worklogs already in Jira.
+func (ce CommandEngine) addWorklogCommands() {
+	var Worklog = &cobra.Command{
+		Use:   worklogCMD,
+		Short: "edits or deletes your worklogs",
+	}
+
+	var Edit = &cobra.Command{
+		Use:   "edit",
+		Short: "changes the duration, start time or comment of a worklog",
+		Example: `worklog edit --id 10234 -t 4
+worklog edit -i GAIA-1232 -d 12/07/2024 --start 09:30 -m "code review"   # asks which one when there are several
+worklog edit -i GAIA-1232 --start "11/07/2024 14:00"
+worklog edit --id 10234 --editor   # rewrites the comment in $EDITOR`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issue, worklog, err := ce.chooseWorklog(cmd)
+			if err != nil {
+				return err
+			}
+
+			var update service.WorklogUpdate
+
+			if cmd.Flags().Changed("time") {
+				timeText, _ := cmd.Flags().GetString("time")
+				spent, err := utils.ParseDuration(timeText)
+				if err != nil {
+					return err
+				}
+				if spent < time.Minute {
+					return errors.New("bad flag combination, the time must be a minute at least")
+				}
+				update.TimeSpentSeconds = int(spent / time.Second)
+			}
+
+			if cmd.Flags().Changed("start") {
+				start, _ := cmd.Flags().GetString("start")
+				update.Started, err = parseStart(start, worklog.Started.Time)
+				if err != nil {
+					return err
+				}
+			}
+
+			message, given, err := commentMessage(cmd, worklog.CommentMarkdown())
+			if err != nil {
+				return err
+			}
+			if given {
+				update.Comment = &message
+			}
+
+			if cmd.Flags().Changed("visibility") {
+				update.Visibility = cmd.Flags().Lookup("visibility").Value.(*utils.Visibility)
+			}
+
+			if update.IsZero() {
+				return errors.New("bad flag combination, nothing to change, give --time, --start, a comment or --visibility")
+			}
+
+			return ce.js.UpdateWorklog(cmd.Context(), issue, worklog.Id, update)
+		},
+	}
+
+	var Delete = &cobra.Command{
+		Use:   "delete",
+		Short: "deletes a worklog",
+		Example: `worklog delete --id 10234
+worklog delete -i GAIA-1232 -d 12/07/2024   # asks which one when there are several`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issue, worklog, err := ce.chooseWorklog(cmd)
+			if err != nil {
+				return err
+			}
+
+			return ce.js.DeleteWorklog(cmd.Context(), issue, worklog.Id, service.DeleteAdjustment{})
+		},
+	}
+
+	for _, command := range []*cobra.Command{Edit, Delete} {
+		command.Flags().String("id", "", "the id of the worklog")
+		command.Flags().StringP("issueKey", "i", "", "the issue of the worklog, when it is not given by id")
+		command.Flags().StringP("date", "d", utils.TODAY_FLAG, "the day the worklog started on, "+dateUsage+", when it is not given by id")
+	}
+
+	Edit.Flags().StringP("time", "t", "", "the new time spent, in hours like 2.5 or in Jira's notation like 1h 30m")
+	Edit.Flags().String("start", "", "the new start, a time like 09:30, a date like the --date ones or both like \"12/07/2024 09:30\"")
+	Edit.Flags().StringP("message", "m", "", "the new comment, in Markdown")
+	addCommentFlags(Edit)
+
+	Worklog.AddCommand(Edit, Delete)
+	ce.RootCmd.AddCommand(Worklog)
+
+	ce.AllCommands[worklogCMD] = Worklog
+}
+
+// chooseWorklog finds the worklog selected by --id, or else by --issueKey
+// and --date, asking which one when the user has several on that day. It
+// returns the worklog with the key or the id of its issue.
+func (ce CommandEngine) chooseWorklog(cmd *cobra.Command) (string, service.WorklogResponseObject, error) {
+	id, _ := cmd.Flags().GetString("id")
+	issue, _ := cmd.Flags().GetString("issueKey")
+	date, _ := cmd.Flags().GetString("date")
+
+	if id != "" {
+		if issue != "" || cmd.Flags().Changed("date") {
+			return "", service.WorklogResponseObject{}, errors.New("bad flag combination, --id cannot be combined with --issueKey or --date")
+		}
+
+		worklog, err := ce.js.GetWorklog(cmd.Context(), id)
+		return worklog.IssueId, worklog, err
+	}
+
+	if issue == "" {
+		return "", service.WorklogResponseObject{}, errors.New("bad flag combination, --id or --issueKey is required")
+	}
+
+	day := time.Now()
+	if date != utils.TODAY_FLAG {
+		var err error
+		day, err = utils.ParseDate(date)
+		if err != nil {
+			return "", service.WorklogResponseObject{}, err
+		}
+	}
+
+	days, err := period.Between(day, day)
+	if err != nil {
+		return "", service.WorklogResponseObject{}, err
+	}
+
+	worklogs, err := ce.js.FindWorklogs(cmd.Context(), issue, days)
+	if err != nil {
+		return "", service.WorklogResponseObject{}, err
+	}
+
+	switch len(worklogs) {
+	case 0:
+		return "", service.WorklogResponseObject{}, fmt.Errorf("you have no worklog on %s on %s", issue, days.From.Format(time.DateOnly))
+	case 1:
+		return issue, worklogs[0], nil
+	}
+
+	rows := make([][]string, len(worklogs))
+	for i, worklog := range worklogs {
+		rows[i] = []string{strconv.Itoa(i + 1), worklog.Id, worklog.Started.Format("15:04"), utils.FormatDuration(worklog.Duration()), worklog.CommentText()}
+	}
+	utils.DrawRows([]string{"#", "Worklog", "Started", "Duration", "Comment"}, rows)
+
+	in := bufio.NewReader(cmd.InOrStdin())
+	for {
+		fmt.Printf("Which worklog? [1-%d]: ", len(worklogs))
+
+		answer, err := in.ReadString('\n')
+		if choice, convErr := strconv.Atoi(strings.TrimSpace(answer)); convErr == nil && choice >= 1 && choice <= len(worklogs) {
+			return issue, worklogs[choice-1], nil
+		}
+
+		if err != nil {
+			fmt.Println()
+			return "", service.WorklogResponseObject{}, errors.New("no worklog was chosen")
+		}
+	}
+}
+
+// parseStart reads the new start of a worklog started at current: a time of
+// day such as 09:30, a date keeping the time of day, or a date followed by a
+// time of day, i.e "last friday 09:30".
+func parseStart(value string, current time.Time) (time.Time, error) {
+	current = current.Local()
+	date := current
+	clock := current
+
+	fields := strings.Fields(value)
+	if len(fields) > 0 {
+		if parsed, err := time.Parse("15:04", fields[len(fields)-1]); err == nil {
+			clock = parsed
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	if len(fields) > 0 {
+		var err error
+		date, err = utils.ParseDate(strings.Join(fields, " "))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse start %q, expected hh:mm, a date or both: %w", value, err)
+		}
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local), nil
+}
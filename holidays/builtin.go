@@ -0,0 +1,189 @@
+package holidays
+
+import (
+	"sort"
+	"time"
+)
+
+// builtIn computes the national public holidays of a country for a year.
+var builtIn = map[string]func(year int) []Holiday{
+	"RO": romania,
+	"DE": germany,
+	"GB": unitedKingdom,
+	"US": unitedStates,
+}
+
+// Countries lists the countries with built-in holidays.
+func Countries() []string {
+	countries := make([]string, 0, len(builtIn))
+	for country := range builtIn {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+
+	return countries
+}
+
+func romania(year int) []Holiday {
+	easter := orthodoxEaster(year)
+
+	holidays := []Holiday{
+		{Date: date(year, time.January, 1), Name: "New Year's Day"},
+		{Date: date(year, time.January, 2), Name: "Day after New Year's Day"},
+		{Date: date(year, time.January, 24), Name: "Union Day"},
+		{Date: easter, Name: "Easter Sunday"},
+		{Date: easter.AddDate(0, 0, 1), Name: "Easter Monday"},
+		{Date: date(year, time.May, 1), Name: "Labour Day"},
+		{Date: date(year, time.June, 1), Name: "Children's Day"},
+		{Date: easter.AddDate(0, 0, 49), Name: "Pentecost"},
+		{Date: easter.AddDate(0, 0, 50), Name: "Whit Monday"},
+		{Date: date(year, time.August, 15), Name: "Assumption of Mary"},
+		{Date: date(year, time.November, 30), Name: "St Andrew's Day"},
+		{Date: date(year, time.December, 1), Name: "National Day"},
+		{Date: date(year, time.December, 25), Name: "Christmas Day"},
+		{Date: date(year, time.December, 26), Name: "Second day of Christmas"},
+	}
+
+	if year >= 2018 {
+		holidays = append(holidays, Holiday{Date: easter.AddDate(0, 0, -2), Name: "Good Friday"})
+	}
+
+	if year >= 2024 {
+		holidays = append(holidays,
+			Holiday{Date: date(year, time.January, 6), Name: "Epiphany"},
+			Holiday{Date: date(year, time.January, 7), Name: "St John the Baptist"},
+		)
+	}
+
+	return holidays
+}
+
+func germany(year int) []Holiday {
+	easter := westernEaster(year)
+
+	return []Holiday{
+		{Date: date(year, time.January, 1), Name: "New Year's Day"},
+		{Date: easter.AddDate(0, 0, -2), Name: "Good Friday"},
+		{Date: easter.AddDate(0, 0, 1), Name: "Easter Monday"},
+		{Date: date(year, time.May, 1), Name: "Labour Day"},
+		{Date: easter.AddDate(0, 0, 39), Name: "Ascension Day"},
+		{Date: easter.AddDate(0, 0, 50), Name: "Whit Monday"},
+		{Date: date(year, time.October, 3), Name: "German Unity Day"},
+		{Date: date(year, time.December, 25), Name: "Christmas Day"},
+		{Date: date(year, time.December, 26), Name: "Boxing Day"},
+	}
+}
+
+// unitedKingdom has the bank holidays of England and Wales.
+func unitedKingdom(year int) []Holiday {
+	easter := westernEaster(year)
+
+	newYear := date(year, time.January, 1)
+	for newYear.Weekday() == time.Saturday || newYear.Weekday() == time.Sunday {
+		newYear = newYear.AddDate(0, 0, 1)
+	}
+
+	// a Christmas or Boxing Day on the weekend moves to the next free weekday
+	christmas := date(year, time.December, 25)
+	boxingDay := date(year, time.December, 26)
+	switch christmas.Weekday() {
+	case time.Friday:
+		boxingDay = date(year, time.December, 28)
+	case time.Saturday:
+		christmas, boxingDay = date(year, time.December, 27), date(year, time.December, 28)
+	case time.Sunday:
+		christmas = date(year, time.December, 27)
+	}
+
+	return []Holiday{
+		{Date: newYear, Name: "New Year's Day"},
+		{Date: easter.AddDate(0, 0, -2), Name: "Good Friday"},
+		{Date: easter.AddDate(0, 0, 1), Name: "Easter Monday"},
+		{Date: nthWeekday(year, time.May, time.Monday, 1), Name: "Early May bank holiday"},
+		{Date: nthWeekday(year, time.May, time.Monday, -1), Name: "Spring bank holiday"},
+		{Date: nthWeekday(year, time.August, time.Monday, -1), Name: "Summer bank holiday"},
+		{Date: christmas, Name: "Christmas Day"},
+		{Date: boxingDay, Name: "Boxing Day"},
+	}
+}
+
+// unitedStates has the federal holidays, on the day they are observed.
+func unitedStates(year int) []Holiday {
+	return []Holiday{
+		{Date: observed(date(year, time.January, 1)), Name: "New Year's Day"},
+		{Date: nthWeekday(year, time.January, time.Monday, 3), Name: "Martin Luther King Jr. Day"},
+		{Date: nthWeekday(year, time.February, time.Monday, 3), Name: "Washington's Birthday"},
+		{Date: nthWeekday(year, time.May, time.Monday, -1), Name: "Memorial Day"},
+		{Date: observed(date(year, time.June, 19)), Name: "Juneteenth"},
+		{Date: observed(date(year, time.July, 4)), Name: "Independence Day"},
+		{Date: nthWeekday(year, time.September, time.Monday, 1), Name: "Labor Day"},
+		{Date: nthWeekday(year, time.October, time.Monday, 2), Name: "Columbus Day"},
+		{Date: observed(date(year, time.November, 11)), Name: "Veterans Day"},
+		{Date: nthWeekday(year, time.November, time.Thursday, 4), Name: "Thanksgiving Day"},
+		{Date: observed(date(year, time.December, 25)), Name: "Christmas Day"},
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// observed moves a holiday falling on Saturday to Friday and on Sunday to Monday.
+func observed(day time.Time) time.Time {
+	switch day.Weekday() {
+	case time.Saturday:
+		return day.AddDate(0, 0, -1)
+	case time.Sunday:
+		return day.AddDate(0, 0, 1)
+	default:
+		return day
+	}
+}
+
+// nthWeekday is the nth weekday of the month, counting from its end when n is negative.
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := date(year, month+1, 0)
+		back := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -back+7*(n+1))
+	}
+
+	first := date(year, month, 1)
+	forward := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, forward+7*(n-1))
+}
+
+// westernEaster computes the Gregorian Easter Sunday with the anonymous Gregorian algorithm.
+func westernEaster(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return date(year, time.Month(month), day)
+}
+
+// orthodoxEaster computes the Julian Easter Sunday with Meeus' algorithm,
+// in the Gregorian calendar.
+func orthodoxEaster(year int) time.Time {
+	a := year % 4
+	b := year % 7
+	c := year % 19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+
+	// the Julian calendar lags 13 days behind from 1900 to 2099
+	return date(year, time.Month(month), day).AddDate(0, 0, 13)
+}
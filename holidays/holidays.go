@@ -0,0 +1,183 @@
+// Package holidays knows which days are public or company holidays, from
+// built-in national calendars and from ICS or JSON files.
+package holidays
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Holiday struct {
+	Date time.Time
+	Name string
+	// Yearly holidays fall on the month and day of Date every year.
+	Yearly bool
+}
+
+// Calendar answers whether a day is a holiday. The zero value and a nil
+// Calendar have no holidays.
+type Calendar struct {
+	country string
+
+	mu     sync.Mutex
+	days   map[string]string
+	yearly map[string]string
+	years  map[int]bool
+}
+
+// Load builds the calendar of the built-in holidays of country, which may
+// be empty, merged with the holidays listed in file, which may be empty too.
+func Load(country string, file string) (*Calendar, error) {
+	calendar, err := New(country)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		if err := calendar.LoadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	return calendar, nil
+}
+
+// New builds a calendar with the built-in holidays of country, i.e "RO".
+func New(country string) (*Calendar, error) {
+	country = strings.ToUpper(country)
+
+	if _, ok := builtIn[country]; country != "" && !ok {
+		return nil, fmt.Errorf("no built-in holidays for %q, known countries are %s", country, Countries())
+	}
+
+	return &Calendar{country: country}, nil
+}
+
+// Add marks the day of holiday as a holiday.
+func (c *Calendar) Add(holiday Holiday) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(holiday)
+}
+
+// add stores holiday. c.mu must be held.
+func (c *Calendar) add(holiday Holiday) {
+	if c.days == nil {
+		c.days = map[string]string{}
+		c.yearly = map[string]string{}
+	}
+
+	if holiday.Yearly {
+		c.yearly[holiday.Date.Format("01-02")] = holiday.Name
+		return
+	}
+
+	c.days[holiday.Date.Format(time.DateOnly)] = holiday.Name
+}
+
+// LoadFile adds the holidays listed in an .ics calendar or in a .json file
+// shaped like [{"date": "2024-12-25", "name": "Christmas Day"}], where a
+// date without year, i.e "12-25", is a holiday every year.
+func (c *Calendar) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var holidays []Holiday
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics":
+		holidays, err = parseICS(string(data))
+	case ".json":
+		holidays, err = parseJSON(data)
+	default:
+		return fmt.Errorf("holidays file %s must be an .ics or a .json file", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("reading holidays from %s: %w", path, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, holiday := range holidays {
+		c.add(holiday)
+	}
+
+	return nil
+}
+
+// Holiday returns the name of the holiday falling on the day of date.
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// a holiday may be observed in the year next to its own, i.e New Year's
+	// Day falling on a Saturday is observed on Friday, December 31
+	for year := date.Year() - 1; c.country != "" && year <= date.Year()+1; year++ {
+		if c.years[year] {
+			continue
+		}
+		if c.years == nil {
+			c.years = map[int]bool{}
+		}
+		c.years[year] = true
+
+		for _, holiday := range builtIn[c.country](year) {
+			c.add(holiday)
+		}
+	}
+
+	if name, ok := c.days[date.Format(time.DateOnly)]; ok {
+		return name, true
+	}
+
+	name, ok := c.yearly[date.Format("01-02")]
+	return name, ok
+}
+
+// IsHoliday reports whether the day of date is a holiday.
+func (c *Calendar) IsHoliday(date time.Time) bool {
+	_, ok := c.Holiday(date)
+	return ok
+}
+
+func parseJSON(data []byte) ([]Holiday, error) {
+	var entries []struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	holidays := make([]Holiday, 0, len(entries))
+	for _, entry := range entries {
+		if date, err := time.Parse(time.DateOnly, entry.Date); err == nil {
+			holidays = append(holidays, Holiday{Date: date, Name: entry.Name})
+			continue
+		}
+
+		date, err := time.Parse("01-02", entry.Date)
+		if err != nil {
+			return nil, fmt.Errorf("date %q must be yyyy-mm-dd, or mm-dd for every year", entry.Date)
+		}
+
+		holidays = append(holidays, Holiday{Date: date, Name: entry.Name, Yearly: true})
+	}
+
+	return holidays, nil
+}
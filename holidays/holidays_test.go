@@ -0,0 +1,39 @@
+package holidays
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuiltInHoliday(t *testing.T) {
+	tests := []struct {
+		country string
+		date    time.Time
+		want    string
+	}{
+		// New Year's Day 2022 fell on a Saturday
+		{country: "US", date: date(2021, time.December, 31), want: "New Year's Day"},
+		{country: "US", date: date(2022, time.January, 1)},
+		// and New Year's Day 2023 on a Sunday
+		{country: "US", date: date(2023, time.January, 2), want: "New Year's Day"},
+		{country: "US", date: date(2024, time.July, 4), want: "Independence Day"},
+		{country: "GB", date: date(2022, time.December, 27), want: "Christmas Day"},
+		{country: "RO", date: date(2024, time.May, 6), want: "Easter Monday"},
+		{country: "DE", date: date(2024, time.October, 3), want: "German Unity Day"},
+		{country: "DE", date: date(2024, time.October, 4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country+" "+tt.date.Format(time.DateOnly), func(t *testing.T) {
+			calendar, err := Load(tt.country, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			name, ok := calendar.Holiday(tt.date)
+			if name != tt.want || ok != (tt.want != "") {
+				t.Errorf("Holiday(%s) = %q, %t, want %q", tt.date.Format(time.DateOnly), name, ok, tt.want)
+			}
+		})
+	}
+}
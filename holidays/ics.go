@@ -0,0 +1,99 @@
+package holidays
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseICS reads the all day events of an iCalendar file as holidays. Events
+// lasting several days mark each of their days, FREQ=YEARLY events recur.
+func parseICS(data string) ([]Holiday, error) {
+	var holidays []Holiday
+	var event map[string]string
+
+	for _, line := range unfoldICS(data) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		// drop the parameters, i.e DTSTART;VALUE=DATE
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = map[string]string{}
+		case name == "END" && value == "VEVENT":
+			if event == nil {
+				continue
+			}
+
+			eventHolidays, err := icsEvent(event)
+			if err != nil {
+				return nil, err
+			}
+			holidays = append(holidays, eventHolidays...)
+			event = nil
+		case event != nil:
+			event[name] = value
+		}
+	}
+
+	return holidays, nil
+}
+
+func icsEvent(event map[string]string) ([]Holiday, error) {
+	start, err := icsDate(event["DTSTART"])
+	if err != nil {
+		return nil, err
+	}
+
+	// DTEND is exclusive and may be left out for single days
+	end := start.AddDate(0, 0, 1)
+	if event["DTEND"] != "" {
+		if end, err = icsDate(event["DTEND"]); err != nil {
+			return nil, err
+		}
+	}
+
+	name := unescapeICS(event["SUMMARY"])
+	yearly := strings.Contains(strings.ToUpper(event["RRULE"]), "FREQ=YEARLY")
+
+	var holidays []Holiday
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		holidays = append(holidays, Holiday{Date: date, Name: name, Yearly: yearly})
+	}
+
+	return holidays, nil
+}
+
+// icsDate reads the day of an ICS DATE or DATE-TIME value.
+func icsDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid ics date %q", value)
+	}
+
+	return time.Parse("20060102", value[:8])
+}
+
+// unfoldICS splits data into content lines, joining the continuation lines
+// that start with a space or a tab.
+func unfoldICS(data string) []string {
+	var lines []string
+
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func unescapeICS(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,681 @@
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	myselfPath    = regexp.MustCompile(`^/rest/api/[23]/myself$`)
+	fieldPath     = regexp.MustCompile(`^/rest/api/[23]/field$`)
+	issuePath     = regexp.MustCompile(`^/rest/api/[23]/issue/([^/]+)$`)
+	worklogPath   = regexp.MustCompile(`^/rest/api/[23]/issue/([^/]+)/worklog$`)
+	transitions   = regexp.MustCompile(`^/rest/api/[23]/issue/([^/]+)/transitions$`)
+	worklogIdPath = regexp.MustCompile(`^/rest/api/[23]/issue/([^/]+)/worklog/([^/]+)$`)
+	worklogDates  = regexp.MustCompile(`worklogDate\s*(>=|<)\s*"([^"]+)"`)
+)
+
+func (srv *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.requests = append(srv.requests, r.Method+" "+r.URL.Path)
+
+	if authorization := r.Header.Get("Authorization"); authorization == "" || srv.Token != "" && authorization != "Bearer "+srv.Token {
+		writeError(w, http.StatusUnauthorized, "You are not authenticated. Authentication required to perform this operation.")
+		return
+	}
+
+	if failure := srv.failure(r); failure != nil {
+		for name, values := range failure.Header {
+			w.Header()[name] = values
+		}
+
+		if failure.Malformed {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"malformed": [`))
+			return
+		}
+
+		if failure.Body != "" {
+			w.WriteHeader(failure.Status)
+			w.Write([]byte(failure.Body))
+			return
+		}
+
+		writeError(w, failure.Status, http.StatusText(failure.Status))
+		return
+	}
+
+	path := r.URL.Path
+	cloud := srv.DeploymentType == "Cloud"
+
+	switch {
+	case !cloud && strings.HasPrefix(path, "/rest/api/3/"):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No resource found for %s %s", r.Method, path))
+	case strings.HasSuffix(path, "/serverInfo") && r.Method == http.MethodGet:
+		version := "1001.0.0"
+		if !cloud {
+			version = "9.12.0"
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"deploymentType": srv.DeploymentType, "version": version})
+	case myselfPath.MatchString(path) && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, userJSON(srv.user))
+	case fieldPath.MatchString(path) && r.Method == http.MethodGet:
+		srv.serveFields(w)
+	case cloud && path == "/rest/api/3/search/jql" && r.Method == http.MethodPost:
+		srv.serveSearch(w, r, false)
+	case !cloud && path == "/rest/api/2/search" && r.Method == http.MethodPost:
+		srv.serveSearch(w, r, true)
+	case issuePath.MatchString(path) && r.Method == http.MethodGet:
+		srv.serveIssue(w, issuePath.FindStringSubmatch(path)[1])
+	case transitions.MatchString(path):
+		issueKey := transitions.FindStringSubmatch(path)[1]
+
+		switch r.Method {
+		case http.MethodGet:
+			srv.serveTransitions(w, issueKey)
+		case http.MethodPost:
+			srv.servePostTransition(w, r, issueKey)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case worklogPath.MatchString(path):
+		issueKey := worklogPath.FindStringSubmatch(path)[1]
+
+		switch r.Method {
+		case http.MethodGet:
+			srv.serveWorklogs(w, r, issueKey)
+		case http.MethodPost:
+			srv.servePostWorklog(w, r, issueKey)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case worklogIdPath.MatchString(path):
+		match := worklogIdPath.FindStringSubmatch(path)
+
+		switch r.Method {
+		case http.MethodPut:
+			srv.servePutWorklog(w, r, match[1], match[2])
+		case http.MethodDelete:
+			srv.serveDeleteWorklog(w, r, match[1], match[2])
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case strings.HasSuffix(path, "/worklog/list") && r.Method == http.MethodPost:
+		srv.serveWorklogList(w, r)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No resource found for %s %s", r.Method, path))
+	}
+}
+
+func (srv *Server) serveFields(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, []map[string]any{
+		{"id": "summary", "key": "summary", "name": "Summary", "custom": false},
+		{"id": "timetracking", "key": "timetracking", "name": "Time tracking", "custom": false},
+		{"id": "worklog", "key": "worklog", "name": "Log Work", "custom": false},
+		{"id": "updated", "key": "updated", "name": "Updated", "custom": false},
+	})
+}
+
+// serveSearch answers the JQL search, paged by nextPageToken on Cloud and
+// by startAt on Server. The only clauses understood are the worklogDate
+// bounds jira-cli sends, anything else matches every issue.
+func (srv *Server) serveSearch(w http.ResponseWriter, r *http.Request, byStartAt bool) {
+	var body struct {
+		Jql           string `json:"jql"`
+		MaxResults    int    `json:"maxResults"`
+		NextPageToken string `json:"nextPageToken"`
+		StartAt       int    `json:"startAt"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	var from, to time.Time
+	for _, match := range worklogDates.FindAllStringSubmatch(body.Jql, -1) {
+		date, err := time.ParseInLocation("2006/01/02", match[2], time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Date value '%s' for field 'worklogDate' is invalid.", match[2]))
+			return
+		}
+
+		if match[1] == ">=" {
+			from = date
+		} else {
+			to = date
+		}
+	}
+
+	var matching []Issue
+	for _, issue := range srv.issues {
+		if from.IsZero() && to.IsZero() || srv.hasWorklogBetween(issue.Key, from, to) {
+			matching = append(matching, issue)
+		}
+	}
+
+	start, _ := strconv.Atoi(body.NextPageToken)
+	if byStartAt {
+		start = body.StartAt
+	}
+	end := min(start+srv.pageSize(body.MaxResults), len(matching))
+	start = min(start, end)
+
+	issues := []map[string]any{}
+	for _, issue := range matching[start:end] {
+		issues = append(issues, map[string]any{
+			"id":  issue.Id,
+			"key": issue.Key,
+			"fields": map[string]any{
+				"summary": issue.Summary,
+				"updated": issue.Updated.Format(timeLayout),
+			},
+		})
+	}
+
+	response := map[string]any{"issues": issues}
+	if byStartAt {
+		response["startAt"] = start
+		response["maxResults"] = srv.pageSize(body.MaxResults)
+		response["total"] = len(matching)
+	} else if end < len(matching) {
+		response["nextPageToken"] = strconv.Itoa(end)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// hasWorklogBetween reports whether issueKey has a worklog started in [from, to), zero bounds are open.
+func (srv *Server) hasWorklogBetween(issueKey string, from time.Time, to time.Time) bool {
+	for _, worklog := range srv.worklogs {
+		if worklog.IssueKey != issueKey {
+			continue
+		}
+
+		if !from.IsZero() && worklog.Started.Before(from) {
+			continue
+		}
+
+		if !to.IsZero() && !worklog.Started.Before(to) {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// serveIssue answers with the summary, the last update and the time
+// tracking of an issue, whatever fields are asked for.
+func (srv *Server) serveIssue(w http.ResponseWriter, issueKey string) {
+	issue := srv.issue(issueKey)
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	spent := 0
+	for _, worklog := range srv.worklogs {
+		if worklog.IssueKey == issue.Key {
+			spent += worklog.TimeSpentSeconds
+		}
+	}
+
+	tracking := map[string]any{}
+	if issue.Estimated {
+		tracking["originalEstimate"] = formatTimeSpent(issue.OriginalEstimateSeconds)
+		tracking["originalEstimateSeconds"] = issue.OriginalEstimateSeconds
+		tracking["remainingEstimate"] = formatTimeSpent(issue.RemainingEstimateSeconds)
+		tracking["remainingEstimateSeconds"] = issue.RemainingEstimateSeconds
+	}
+	if spent > 0 {
+		tracking["timeSpent"] = formatTimeSpent(spent)
+		tracking["timeSpentSeconds"] = spent
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":  issue.Id,
+		"key": issue.Key,
+		"fields": map[string]any{
+			"summary":      issue.Summary,
+			"updated":      issue.Updated.Format(timeLayout),
+			"timetracking": tracking,
+			"status":       map[string]any{"name": issue.Status},
+		},
+	})
+}
+
+// serveTransitions lists the transitions to every status but the one the issue is in.
+func (srv *Server) serveTransitions(w http.ResponseWriter, issueKey string) {
+	issue := srv.issue(issueKey)
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	list := []map[string]any{}
+	for i, status := range Statuses {
+		if status == issue.Status {
+			continue
+		}
+
+		list = append(list, map[string]any{
+			"id":   transitionId(i),
+			"name": status,
+			"to":   map[string]any{"name": status},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"transitions": list})
+}
+
+func (srv *Server) servePostTransition(w http.ResponseWriter, r *http.Request, issueKey string) {
+	issue := srv.issue(issueKey)
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var body struct {
+		Transition struct {
+			Id string `json:"id"`
+		} `json:"transition"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Can not deserialize the request body.")
+		return
+	}
+
+	for i, status := range Statuses {
+		if transitionId(i) == body.Transition.Id && status != issue.Status {
+			issue.Status = status
+			issue.Updated = time.Now()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	writeFieldError(w, "transition", "Transition id '"+body.Transition.Id+"' is not valid for this issue.")
+}
+
+// transitionId is the id of the transition to the i-th of Statuses, numbered the way Jira does.
+func transitionId(i int) string {
+	return strconv.Itoa(11 + 10*i)
+}
+
+func (srv *Server) serveWorklogs(w http.ResponseWriter, r *http.Request, issueKey string) {
+	issue := srv.issue(issueKey)
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	issueKey = issue.Key
+
+	query := r.URL.Query()
+	startAt, _ := strconv.Atoi(query.Get("startAt"))
+	maxResults, _ := strconv.Atoi(query.Get("maxResults"))
+	startedAfter, _ := strconv.ParseInt(query.Get("startedAfter"), 10, 64)
+	startedBefore, _ := strconv.ParseInt(query.Get("startedBefore"), 10, 64)
+
+	var matching []Worklog
+	for _, worklog := range srv.worklogs {
+		if worklog.IssueKey != issueKey {
+			continue
+		}
+
+		started := worklog.Started.UnixMilli()
+		if query.Has("startedAfter") && started <= startedAfter {
+			continue
+		}
+
+		if query.Has("startedBefore") && started >= startedBefore {
+			continue
+		}
+
+		matching = append(matching, worklog)
+	}
+
+	pageSize := srv.pageSize(maxResults)
+	end := min(startAt+pageSize, len(matching))
+	startAt = min(startAt, end)
+
+	worklogs := []map[string]any{}
+	for _, worklog := range matching[startAt:end] {
+		worklogs = append(worklogs, srv.worklogJSON(worklog))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"startAt":    startAt,
+		"maxResults": pageSize,
+		"total":      len(matching),
+		"worklogs":   worklogs,
+	})
+}
+
+func (srv *Server) servePostWorklog(w http.ResponseWriter, r *http.Request, issueKey string) {
+	issue := srv.issue(issueKey)
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	issueKey = issue.Key
+
+	var body struct {
+		Comment          any         `json:"comment"`
+		Started          string      `json:"started"`
+		TimeSpentSeconds int         `json:"timeSpentSeconds"`
+		Visibility       *Visibility `json:"visibility"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	started, err := time.Parse(timeLayout, body.Started)
+	if err != nil {
+		writeFieldError(w, "started", "Invalid date format. Please enter the date in the format \"yyyy-MM-dd'T'HH:mm:ss.SSSZ\".")
+		return
+	}
+
+	if body.TimeSpentSeconds <= 0 {
+		writeFieldError(w, "timeLogged", "You must indicate the time spent working.")
+		return
+	}
+
+	if !validVisibility(body.Visibility) {
+		writeFieldError(w, "visibility", "Visibility must be a group or a project role with a name.")
+		return
+	}
+
+	remaining, err := adjustEstimate(r.URL.Query(), issue.RemainingEstimateSeconds, body.TimeSpentSeconds)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if issue.Estimated {
+		issue.RemainingEstimateSeconds = remaining
+	}
+
+	worklog := srv.addWorklog(Worklog{
+		IssueKey:         issueKey,
+		Author:           srv.user,
+		Comment:          body.Comment,
+		Started:          started,
+		TimeSpentSeconds: body.TimeSpentSeconds,
+		Visibility:       body.Visibility,
+	})
+
+	writeJSON(w, http.StatusCreated, srv.worklogJSON(worklog))
+}
+
+// validVisibility reports whether visibility, when given, names a group or a role.
+func validVisibility(visibility *Visibility) bool {
+	return visibility == nil || (visibility.Type == "group" || visibility.Type == "role") && visibility.Value != ""
+}
+
+// adjustEstimate is the remaining estimate, in seconds, once a worklog of
+// spent seconds is posted with the adjustEstimate, newEstimate and reduceBy
+// parameters of query. A deleted worklog has spent negative, its time going
+// back to the estimate, and increaseBy instead of reduceBy. Estimates are
+// read in hours and minutes only.
+func adjustEstimate(query url.Values, remaining int, spent int) (int, error) {
+	estimate := func(name string) (int, error) {
+		d, err := time.ParseDuration(strings.ReplaceAll(query.Get(name), " ", ""))
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("Invalid %s '%s'.", name, query.Get(name))
+		}
+		return int(d / time.Second), nil
+	}
+
+	switch query.Get("adjustEstimate") {
+	case "", "auto":
+		remaining -= spent
+	case "leave":
+	case "new":
+		seconds, err := estimate("newEstimate")
+		if err != nil {
+			return 0, err
+		}
+		remaining = seconds
+	case "manual":
+		if spent < 0 {
+			seconds, err := estimate("increaseBy")
+			if err != nil {
+				return 0, err
+			}
+			remaining += seconds
+			break
+		}
+		seconds, err := estimate("reduceBy")
+		if err != nil {
+			return 0, err
+		}
+		remaining -= seconds
+	default:
+		return 0, fmt.Errorf("Invalid adjustEstimate '%s'.", query.Get("adjustEstimate"))
+	}
+
+	return max(remaining, 0), nil
+}
+
+// servePutWorklog updates the fields present in the body, issue being the
+// key or the id of the worklog's issue.
+func (srv *Server) servePutWorklog(w http.ResponseWriter, r *http.Request, issue string, id string) {
+	worklog := srv.issueWorklog(issue, id)
+	if worklog == nil {
+		writeError(w, http.StatusNotFound, "Cannot find worklog with id: "+id)
+		return
+	}
+
+	var body struct {
+		Comment          any     `json:"comment"`
+		Started          *string `json:"started"`
+		TimeSpentSeconds *int    `json:"timeSpentSeconds"`
+		// Visibility is kept raw, a null lifting the restriction where a
+		// missing one leaves it
+		Visibility json.RawMessage `json:"visibility"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	var visibility *Visibility
+	if body.Visibility != nil {
+		if err := json.Unmarshal(body.Visibility, &visibility); err != nil || !validVisibility(visibility) {
+			writeFieldError(w, "visibility", "Visibility must be a group or a project role with a name.")
+			return
+		}
+	}
+
+	if body.Started != nil {
+		started, err := time.Parse(timeLayout, *body.Started)
+		if err != nil {
+			writeFieldError(w, "started", "Invalid date format. Please enter the date in the format \"yyyy-MM-dd'T'HH:mm:ss.SSSZ\".")
+			return
+		}
+		worklog.Started = started
+	}
+
+	if body.TimeSpentSeconds != nil {
+		if *body.TimeSpentSeconds <= 0 {
+			writeFieldError(w, "timeLogged", "You must indicate the time spent working.")
+			return
+		}
+		worklog.TimeSpentSeconds = *body.TimeSpentSeconds
+	}
+
+	if body.Comment != nil {
+		worklog.Comment = body.Comment
+	}
+
+	if body.Visibility != nil {
+		worklog.Visibility = visibility
+	}
+
+	writeJSON(w, http.StatusOK, srv.worklogJSON(*worklog))
+}
+
+func (srv *Server) serveDeleteWorklog(w http.ResponseWriter, r *http.Request, issue string, id string) {
+	worklog := srv.issueWorklog(issue, id)
+	if worklog == nil {
+		writeError(w, http.StatusNotFound, "Cannot find worklog with id: "+id)
+		return
+	}
+
+	estimated := srv.issue(worklog.IssueKey)
+	remaining, err := adjustEstimate(r.URL.Query(), estimated.RemainingEstimateSeconds, -worklog.TimeSpentSeconds)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if estimated.Estimated {
+		estimated.RemainingEstimateSeconds = remaining
+	}
+
+	for i := range srv.worklogs {
+		if srv.worklogs[i].Id == id {
+			srv.worklogs = append(srv.worklogs[:i], srv.worklogs[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveWorklogList answers the worklogs with the ids in the body, of any issue.
+func (srv *Server) serveWorklogList(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Ids []int `json:"ids"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	worklogs := []map[string]any{}
+	for _, id := range body.Ids {
+		for _, worklog := range srv.worklogs {
+			if worklog.Id == strconv.Itoa(id) {
+				worklogs = append(worklogs, srv.worklogJSON(worklog))
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, worklogs)
+}
+
+// issueWorklog looks up the worklog id of issue, its key or its id. srv.mu must be held.
+func (srv *Server) issueWorklog(issue string, id string) *Worklog {
+	stored := srv.issue(issue)
+	if stored == nil {
+		return nil
+	}
+
+	for i := range srv.worklogs {
+		if srv.worklogs[i].Id == id && srv.worklogs[i].IssueKey == stored.Key {
+			return &srv.worklogs[i]
+		}
+	}
+
+	return nil
+}
+
+// pageSize caps the page size a client asked for to the server's PageSize.
+func (srv *Server) pageSize(requested int) int {
+	if requested <= 0 || requested > srv.PageSize {
+		return srv.PageSize
+	}
+
+	return requested
+}
+
+func (srv *Server) worklogJSON(worklog Worklog) map[string]any {
+	issueId := ""
+	if issue := srv.issue(worklog.IssueKey); issue != nil {
+		issueId = issue.Id
+	}
+
+	body := map[string]any{
+		"id":               worklog.Id,
+		"issueId":          issueId,
+		"author":           userJSON(worklog.Author),
+		"updateAuthor":     userJSON(worklog.Author),
+		"comment":          worklog.Comment,
+		"started":          worklog.Started.Format(timeLayout),
+		"created":          worklog.Created.Format(timeLayout),
+		"updated":          worklog.Created.Format(timeLayout),
+		"timeSpent":        formatTimeSpent(worklog.TimeSpentSeconds),
+		"timeSpentSeconds": worklog.TimeSpentSeconds,
+	}
+	if worklog.Visibility != nil {
+		body["visibility"] = worklog.Visibility
+	}
+
+	return body
+}
+
+func userJSON(user User) map[string]any {
+	body := map[string]any{
+		"active":       true,
+		"displayName":  user.DisplayName,
+		"emailAddress": user.Email,
+	}
+	if user.AccountId != "" {
+		body["accountId"] = user.AccountId
+		body["accountType"] = "atlassian"
+	}
+	if user.Name != "" {
+		body["name"] = user.Name
+		body["key"] = user.Name
+	}
+
+	return body
+}
+
+// formatTimeSpent renders seconds the way Jira fills in timeSpent, i.e "2h 30m".
+func formatTimeSpent(seconds int) string {
+	var parts []string
+
+	if hours := seconds / 3600; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+
+	if minutes := seconds % 3600 / 60; minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"errorMessages": []string{message},
+		"errors":        map[string]string{},
+	})
+}
+
+func writeFieldError(w http.ResponseWriter, field string, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]any{
+		"errorMessages": []string{},
+		"errors":        map[string]string{field: message},
+	})
+}
@@ -0,0 +1,290 @@
+// Package jiratest runs a fake Jira REST API on a local httptest.Server so
+// jira-cli can be exercised end to end without a real Jira instance.
+//
+//	srv := jiratest.NewServer()
+//	defer srv.Close()
+//	srv.AddIssue("GAIA-1", "Some issue")
+//	os.Setenv("JIRA_ENDPOINT", srv.Endpoint())
+package jiratest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeLayout is how Jira formats timestamps.
+const timeLayout = "2006-01-02T15:04:05.000-0700"
+
+// Statuses is the workflow of the issues, any status being reachable from
+// any other.
+var Statuses = []string{"To Do", "In Progress", "Done"}
+
+// User is known by AccountId on Cloud and by Name on Server.
+type User struct {
+	AccountId   string
+	Name        string
+	DisplayName string
+	Email       string
+}
+
+type Issue struct {
+	Id      string
+	Key     string
+	Summary string
+	Updated time.Time
+	// Status is one of Statuses, the first one for new issues
+	Status string
+	// Estimated issues have their remaining estimate adjusted by posted worklogs
+	Estimated                bool
+	OriginalEstimateSeconds  int
+	RemainingEstimateSeconds int
+}
+
+type Worklog struct {
+	Id               string
+	IssueKey         string
+	Author           User
+	Comment          any
+	Started          time.Time
+	TimeSpentSeconds int
+	Created          time.Time
+	// Visibility, when set, restricts the worklog to a group or a project role
+	Visibility *Visibility
+}
+
+type Visibility struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Failure makes the server answer matching requests with an error instead
+// of serving them.
+type Failure struct {
+	// Method and Path select the requests to fail, an empty Method matches
+	// every method and Path matches any request path containing it.
+	Method string
+	Path   string
+	// Status is answered with Body, defaults to 500.
+	Status int
+	Body   string
+	// Header is added to the response, i.e Retry-After for a 429.
+	Header http.Header
+	// Malformed answers 200 with a body that is not valid JSON.
+	Malformed bool
+	// Times is how many requests fail before the server recovers, 0 means forever.
+	Times int
+}
+
+// Server is a fake Jira seeded with a user, issues and worklogs.
+type Server struct {
+	*httptest.Server
+
+	// PageSize caps how many results one page of a search or worklog listing holds.
+	PageSize int
+	// DeploymentType is what serverInfo answers, "Cloud" unless set to
+	// "Server" to act as Jira Server / Data Center, which only serves REST
+	// API v2 and searches by startAt.
+	DeploymentType string
+	// Token, when set, is the personal access token requests must carry as
+	// a Bearer token, as on Server / Data Center. Any credentials are
+	// accepted otherwise.
+	Token string
+
+	mu       sync.Mutex
+	user     User
+	issues   []Issue
+	worklogs []Worklog
+	failures []*Failure
+	requests []string
+	nextId   int
+}
+
+// NewServer starts a fake Jira. It is seeded with a default user and no
+// issues, call Close when done.
+func NewServer() *Server {
+	srv := &Server{
+		PageSize:       50,
+		DeploymentType: "Cloud",
+		user: User{
+			AccountId:   "5b10ac8d82e05b22cc7d4ef5",
+			DisplayName: "Jira Test",
+			Email:       "jira.test@example.com",
+		},
+		nextId: 10000,
+	}
+
+	srv.Server = httptest.NewServer(http.HandlerFunc(srv.serveHTTP))
+
+	return srv
+}
+
+// Endpoint is the value to put in JIRA_ENDPOINT to talk to the server.
+func (srv *Server) Endpoint() string {
+	return srv.URL
+}
+
+// User returns the user requests are authenticated as.
+func (srv *Server) User() User {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return srv.user
+}
+
+func (srv *Server) SetUser(user User) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.user = user
+}
+
+// AddIssue seeds an issue and returns it.
+func (srv *Server) AddIssue(key string, summary string) Issue {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	issue := Issue{
+		Id:      srv.newId(),
+		Key:     key,
+		Summary: summary,
+		Updated: time.Now(),
+		Status:  Statuses[0],
+	}
+	srv.issues = append(srv.issues, issue)
+
+	return issue
+}
+
+// SetEstimate estimates an existing issue, in seconds.
+func (srv *Server) SetEstimate(issueKey string, originalSeconds int, remainingSeconds int) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if issue := srv.issue(issueKey); issue != nil {
+		issue.Estimated = true
+		issue.OriginalEstimateSeconds = originalSeconds
+		issue.RemainingEstimateSeconds = remainingSeconds
+	}
+}
+
+// Issue returns the seeded issue with key, and whether there is one.
+func (srv *Server) Issue(key string) (Issue, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if issue := srv.issue(key); issue != nil {
+		return *issue, true
+	}
+
+	return Issue{}, false
+}
+
+// AddWorklog seeds a worklog on an existing issue, by the server's user when
+// no author is set, and returns it with its id filled in.
+func (srv *Server) AddWorklog(issueKey string, started time.Time, timeSpentSeconds int) Worklog {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return srv.addWorklog(Worklog{
+		IssueKey:         issueKey,
+		Author:           srv.user,
+		Started:          started,
+		TimeSpentSeconds: timeSpentSeconds,
+	})
+}
+
+// Worklogs returns the worklogs of an issue, seeded or posted, oldest first.
+func (srv *Server) Worklogs(issueKey string) []Worklog {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	var worklogs []Worklog
+	for _, worklog := range srv.worklogs {
+		if worklog.IssueKey == issueKey {
+			worklogs = append(worklogs, worklog)
+		}
+	}
+
+	return worklogs
+}
+
+// Fail registers a failure, the ones registered first are tried first.
+func (srv *Server) Fail(failure Failure) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if failure.Status == 0 {
+		failure.Status = http.StatusInternalServerError
+	}
+
+	srv.failures = append(srv.failures, &failure)
+}
+
+// Requests lists the requests served so far as "METHOD /path".
+func (srv *Server) Requests() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return append([]string{}, srv.requests...)
+}
+
+// failure picks the registered failure matching r, if any, and uses it up. srv.mu must be held.
+func (srv *Server) failure(r *http.Request) *Failure {
+	for i, failure := range srv.failures {
+		if failure.Method != "" && failure.Method != r.Method {
+			continue
+		}
+
+		if !strings.Contains(r.URL.Path, failure.Path) {
+			continue
+		}
+
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				srv.failures = append(srv.failures[:i], srv.failures[i+1:]...)
+			}
+		}
+
+		return failure
+	}
+
+	return nil
+}
+
+// issue looks up a seeded issue by key or id, as Jira does. srv.mu must be held.
+func (srv *Server) issue(key string) *Issue {
+	for i := range srv.issues {
+		if srv.issues[i].Key == key || srv.issues[i].Id == key {
+			return &srv.issues[i]
+		}
+	}
+
+	return nil
+}
+
+// addWorklog stores worklog and touches its issue. srv.mu must be held.
+func (srv *Server) addWorklog(worklog Worklog) Worklog {
+	worklog.Id = srv.newId()
+	if worklog.Created.IsZero() {
+		worklog.Created = time.Now()
+	}
+
+	srv.worklogs = append(srv.worklogs, worklog)
+
+	if issue := srv.issue(worklog.IssueKey); issue != nil {
+		issue.Updated = worklog.Created
+	}
+
+	return worklog
+}
+
+// newId hands out ids the way Jira does, as numeric strings. srv.mu must be held.
+func (srv *Server) newId() string {
+	srv.nextId++
+	return strconv.Itoa(srv.nextId)
+}
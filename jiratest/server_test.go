@@ -0,0 +1,411 @@
+package jiratest_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinsimion/jira-cli/jiratest"
+	"github.com/alinsimion/jira-cli/journal"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/service"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+// newService starts a fake Jira and a JiraService talking to it through
+// JIRA_ENDPOINT, the way main wires it.
+func newService(t *testing.T) (*jiratest.Server, *service.JiraService) {
+	t.Helper()
+
+	srv := jiratest.NewServer()
+	t.Cleanup(srv.Close)
+	t.Setenv(utils.JIRA_ENDPOINT, srv.Endpoint())
+
+	js := service.NewJiraService("token", os.Getenv(utils.JIRA_ENDPOINT), srv.User().Email)
+	if err := js.GetMySelf(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return srv, js
+}
+
+// newDataCenterService starts a fake Jira Server / Data Center, which only
+// takes personal access tokens, and a JiraService without an email talking
+// to it with one.
+func newDataCenterService(t *testing.T) (*jiratest.Server, *service.JiraService) {
+	t.Helper()
+
+	srv := jiratest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.DeploymentType = "Server"
+	srv.Token = "personal-access-token"
+	srv.SetUser(jiratest.User{Name: "jdoe", DisplayName: "John Doe", Email: "jdoe@example.com"})
+
+	js := service.NewJiraService(srv.Token, srv.Endpoint(), "")
+	if err := js.GetMySelf(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return srv, js
+}
+
+// cloudRequests lists the requests sent to the REST API v3, which Server
+// does not have.
+func cloudRequests(requests []string) []string {
+	var cloud []string
+	for _, request := range requests {
+		if strings.Contains(request, " /rest/api/3/") {
+			cloud = append(cloud, request)
+		}
+	}
+
+	return cloud
+}
+
+func count(requests []string, request string) int {
+	n := 0
+	for _, served := range requests {
+		if served == request {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestLogWork(t *testing.T) {
+	srv, js := newService(t)
+	srv.AddIssue("GAIA-1", "Some issue")
+
+	started := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+	err := js.LogWork(context.Background(), utils.LogWorkParams{
+		IssueKey:  "GAIA-1",
+		Started:   started,
+		TimeSpent: 2 * time.Hour,
+		Message:   "did **things**",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worklogs := srv.Worklogs("GAIA-1")
+	if len(worklogs) != 1 {
+		t.Fatalf("got %d worklogs, want 1", len(worklogs))
+	}
+	if worklogs[0].TimeSpentSeconds != 7200 || !worklogs[0].Started.Equal(started) {
+		t.Errorf("got %ds started %s, want 7200s started %s", worklogs[0].TimeSpentSeconds, worklogs[0].Started, started)
+	}
+	if worklogs[0].Author != srv.User() {
+		t.Errorf("got author %+v, want %+v", worklogs[0].Author, srv.User())
+	}
+
+	if n := count(srv.Requests(), "POST /rest/api/3/issue/GAIA-1/worklog"); n != 1 {
+		t.Errorf("posted the worklog %d times, want once", n)
+	}
+}
+
+func TestLogWorkDataCenter(t *testing.T) {
+	srv, js := newDataCenterService(t)
+	srv.AddIssue("GAIA-1", "Some issue")
+
+	err := js.LogWork(context.Background(), utils.LogWorkParams{
+		IssueKey:  "GAIA-1",
+		Started:   time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local),
+		TimeSpent: 2 * time.Hour,
+		Message:   "did **things**",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worklogs := srv.Worklogs("GAIA-1")
+	if len(worklogs) != 1 {
+		t.Fatalf("got %d worklogs, want 1", len(worklogs))
+	}
+	// Server takes the comment as text, not as a document
+	if worklogs[0].Comment != "did **things**" {
+		t.Errorf("got comment %#v, want the text as it was given", worklogs[0].Comment)
+	}
+	if worklogs[0].Author != srv.User() {
+		t.Errorf("got author %+v, want %+v", worklogs[0].Author, srv.User())
+	}
+
+	if n := count(srv.Requests(), "POST /rest/api/2/issue/GAIA-1/worklog"); n != 1 {
+		t.Errorf("posted the worklog %d times, want once", n)
+	}
+	if cloud := cloudRequests(srv.Requests()); len(cloud) > 0 {
+		t.Errorf("sent %v to the Cloud API", cloud)
+	}
+}
+
+func TestLogWorkDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		strict  bool
+		wantErr bool
+	}{
+		{name: "over the estimate"},
+		{name: "over the estimate, strictly", strict: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, js := newService(t)
+			srv.AddIssue("GAIA-1", "Some issue")
+			srv.SetEstimate("GAIA-1", 3600, 3600)
+
+			cfg := service.DefaultConfig()
+			cfg.Preview = &service.Preview{DryRun: true}
+			js.Configure(cfg)
+
+			err := js.LogWork(context.Background(), utils.LogWorkParams{
+				IssueKey:  "GAIA-1",
+				Started:   time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local),
+				TimeSpent: 2 * time.Hour,
+				Strict:    tt.strict,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+
+			// the estimate is read, nothing is posted
+			if n := count(srv.Requests(), "GET /rest/api/3/issue/GAIA-1"); n != 1 {
+				t.Errorf("read the estimate %d times, want once", n)
+			}
+			if n := count(srv.Requests(), "POST /rest/api/3/issue/GAIA-1/worklog"); n != 0 {
+				t.Errorf("a dry run posted %d worklogs", n)
+			}
+		})
+	}
+}
+
+func TestUpdateIssue(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		wantErr bool
+		want    string
+		// journaled is the transition recorded, from and to
+		journaled []string
+	}{
+		{name: "next status", status: "In Progress", want: "In Progress", journaled: []string{"To Do", "In Progress"}},
+		{name: "any case", status: "done", want: "Done", journaled: []string{"To Do", "Done"}},
+		{name: "same status", status: "To Do", want: "To Do"},
+		{name: "unknown status", status: "Blocked", wantErr: true, want: "To Do"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, js := newService(t)
+			srv.AddIssue("GAIA-1", "Some issue")
+
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			cfg := service.DefaultConfig()
+			cfg.Journal = journal.New(path, "test")
+			js.Configure(cfg)
+
+			err := js.UpdateIssue(context.Background(), "GAIA-1", tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+
+			if issue, _ := srv.Issue("GAIA-1"); issue.Status != tt.want {
+				t.Errorf("got status %s, want %s", issue.Status, tt.want)
+			}
+
+			runs, err := journal.Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var journaled []string
+			for _, run := range runs {
+				for _, entry := range run.Entries {
+					journaled = append(journaled, entry.FromStatus, entry.ToStatus)
+				}
+			}
+			if !slices.Equal(journaled, tt.journaled) {
+				t.Errorf("journaled %v, want %v", journaled, tt.journaled)
+			}
+		})
+	}
+}
+
+func TestGetUserWorkLogs(t *testing.T) {
+	srv, js := newService(t)
+	srv.PageSize = 2
+
+	day := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+	for _, key := range []string{"GAIA-1", "GAIA-2", "GAIA-3"} {
+		srv.AddIssue(key, "Some issue")
+		srv.AddWorklog(key, day, 3600)
+	}
+	srv.AddWorklog("GAIA-1", day.AddDate(0, 0, 1), 1800)
+	srv.AddWorklog("GAIA-1", day.AddDate(0, 0, 10), 1800)
+
+	days, err := period.Between(day, day.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := js.GetUserWorkLogs(context.Background(), days)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		row  string
+		day  string
+		want []string
+	}{
+		{row: "GAIA-1", day: "4", want: []string{"1h"}},
+		{row: "GAIA-1", day: "5", want: []string{"30m"}},
+		{row: "GAIA-2", day: "4", want: []string{"1h"}},
+		{row: "GAIA-3", day: "4", want: []string{"1h"}},
+		{row: service.TotalRow, day: "4", want: []string{"3h"}},
+		{row: "GAIA-1", day: "14"},
+	}
+
+	for _, tt := range tests {
+		if got := table[tt.row][tt.day]; !slices.Equal(got, tt.want) {
+			t.Errorf("table[%s][%s] = %v, want %v", tt.row, tt.day, got, tt.want)
+		}
+	}
+
+	// three issues and pages of two
+	if n := count(srv.Requests(), "POST /rest/api/3/search/jql"); n != 2 {
+		t.Errorf("searched %d times, want 2 pages", n)
+	}
+}
+
+func TestGetUserWorkLogsDataCenter(t *testing.T) {
+	srv, js := newDataCenterService(t)
+	srv.PageSize = 2
+
+	day := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+	for _, key := range []string{"GAIA-1", "GAIA-2", "GAIA-3"} {
+		srv.AddIssue(key, "Some issue")
+		srv.AddWorklog(key, day, 3600)
+	}
+
+	days, err := period.Between(day, day.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := js.GetUserWorkLogs(context.Background(), days)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the worklogs are told to be the user's by name
+	if got := table[service.TotalRow]["4"]; !slices.Equal(got, []string{"3h"}) {
+		t.Errorf("got a total of %v, want 3h", got)
+	}
+
+	// three issues and pages of two
+	if n := count(srv.Requests(), "POST /rest/api/2/search"); n != 2 {
+		t.Errorf("searched %d times, want 2 pages", n)
+	}
+	if cloud := cloudRequests(srv.Requests()); len(cloud) > 0 {
+		t.Errorf("sent %v to the Cloud API", cloud)
+	}
+}
+
+func TestGetWorkLogsForIssuePaging(t *testing.T) {
+	srv, js := newService(t)
+	srv.PageSize = 2
+
+	srv.AddIssue("GAIA-1", "Some issue")
+	day := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+	for i := range 5 {
+		srv.AddWorklog("GAIA-1", day.AddDate(0, 0, i), 3600)
+	}
+
+	worklogs, err := js.GetWorkLogsForIssue(context.Background(), "GAIA-1", service.WorklogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(worklogs.WorkLogs) != 5 {
+		t.Errorf("got %d worklogs, want 5", len(worklogs.WorkLogs))
+	}
+	if n := count(srv.Requests(), "GET /rest/api/3/issue/GAIA-1/worklog"); n != 3 {
+		t.Errorf("fetched %d pages, want 3", n)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		failure  jiratest.Failure
+		wantErr  bool
+		worklogs int
+		posts    int
+	}{
+		{
+			name: "rate limited",
+			failure: jiratest.Failure{
+				Method: http.MethodPost,
+				Path:   "/worklog",
+				Status: http.StatusTooManyRequests,
+				Header: http.Header{"Retry-After": {"0"}},
+				Times:  2,
+			},
+			worklogs: 1,
+			posts:    3,
+		},
+		{
+			name: "rate limited past the attempts",
+			failure: jiratest.Failure{
+				Method: http.MethodPost,
+				Path:   "/worklog",
+				Status: http.StatusTooManyRequests,
+				Header: http.Header{"Retry-After": {"0"}},
+			},
+			wantErr: true,
+			posts:   service.DefaultMaxAttempts,
+		},
+		{
+			// the worklog may have been created, posting it again could log it twice
+			name: "bad gateway",
+			failure: jiratest.Failure{
+				Method: http.MethodPost,
+				Path:   "/worklog",
+				Status: http.StatusBadGateway,
+				Times:  1,
+			},
+			wantErr: true,
+			posts:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, js := newService(t)
+			srv.AddIssue("GAIA-1", "Some issue")
+			srv.Fail(tt.failure)
+
+			err := js.LogWork(context.Background(), utils.LogWorkParams{
+				IssueKey:  "GAIA-1",
+				Started:   time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local),
+				TimeSpent: time.Hour,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+
+			if n := len(srv.Worklogs("GAIA-1")); n != tt.worklogs {
+				t.Errorf("got %d worklogs, want %d", n, tt.worklogs)
+			}
+			if n := count(srv.Requests(), "POST /rest/api/3/issue/GAIA-1/worklog"); n != tt.posts {
+				t.Errorf("posted %d times, want %d", n, tt.posts)
+			}
+		})
+	}
+}
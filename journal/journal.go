@@ -0,0 +1,254 @@
+// Package journal keeps a local record of every change jira-cli made in
+// Jira, grouped by run, so that a run can be listed and undone.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+// Worklog is the state of a worklog before or after a change.
+type Worklog struct {
+	Started          time.Time `json:"started"`
+	TimeSpentSeconds int       `json:"timeSpentSeconds"`
+	Comment          string    `json:"comment,omitempty"`
+	// Visibility is the group or role the worklog was restricted to, nil
+	// when anyone could see it
+	Visibility *utils.Visibility `json:"visibility,omitempty"`
+}
+
+// Entry is one change made in Jira.
+type Entry struct {
+	Run     string    `json:"run"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	// Reverts is the run an undo run reverted, RevertsEntry the position,
+	// from 1, of the entry of that run the change reverted.
+	Reverts      string `json:"reverts,omitempty"`
+	RevertsEntry int    `json:"revertsEntry,omitempty"`
+	// RevertedBy is the undo run that reverted the entry, set by Read.
+	RevertedBy string `json:"-"`
+
+	Action  string `json:"action"`
+	Issue   string `json:"issue"`
+	Worklog string `json:"worklog,omitempty"`
+	// Before is the worklog as it was, nil when the change created it.
+	Before *Worklog `json:"before,omitempty"`
+	// After holds the values the change set, zero for the ones it left
+	// alone, nil when the change deleted the worklog.
+	After *Worklog `json:"after,omitempty"`
+	// FromStatus and ToStatus are the statuses a transition moved the issue
+	// between, empty for worklog changes.
+	FromStatus string `json:"fromStatus,omitempty"`
+	ToStatus   string `json:"toStatus,omitempty"`
+	// AdjustEstimate is what logging or deleting the worklog did to the
+	// remaining estimate of its issue, empty for Jira's default, and
+	// AdjustBySeconds the amount of a manual adjustment.
+	AdjustEstimate  string `json:"adjustEstimate,omitempty"`
+	AdjustBySeconds int    `json:"adjustBySeconds,omitempty"`
+}
+
+// Journal appends the changes of one run to a file. A nil Journal records
+// nothing.
+type Journal struct {
+	// Reverts is set when the run undoes another run, RevertsEntry to the
+	// position, from 1, of the entry of it being reverted.
+	Reverts      string
+	RevertsEntry int
+
+	path     string
+	run      string
+	command  string
+	mu       sync.Mutex
+	recorded int
+}
+
+// DefaultPath is where the journal is kept unless told otherwise.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "jira-cli", "journal.jsonl")
+}
+
+// New starts a run of command, recorded in the journal at path.
+func New(path string, command string) *Journal {
+	return &Journal{
+		path:    path,
+		run:     time.Now().Format("20060102-150405.000"),
+		command: command,
+	}
+}
+
+// Run is the id of the run changes are recorded under.
+func (j *Journal) Run() string {
+	return j.run
+}
+
+// Record appends entry to the journal under the current run.
+func (j *Journal) Record(entry Entry) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry.Run = j.run
+	entry.Time = time.Now()
+	entry.Command = j.command
+	entry.Reverts = j.Reverts
+	entry.RevertsEntry = j.RevertsEntry
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	j.recorded++
+
+	return nil
+}
+
+// Recorded is how many entries the run recorded so far.
+func (j *Journal) Recorded() int {
+	if j == nil {
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.recorded
+}
+
+// Run groups the entries of one run, in the order they were recorded.
+type Run struct {
+	Id      string
+	Time    time.Time
+	Command string
+	Reverts string
+	// RevertedBy is the undo run that reverted the last of its entries, empty
+	// while some are left, an undo that failed half way through leaving the
+	// rest to be retried.
+	RevertedBy string
+	Entries    []Entry
+}
+
+// Reverted reports whether some of the entries of run were reverted.
+func (run *Run) Reverted() bool {
+	for _, entry := range run.Entries {
+		if entry.RevertedBy != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Read lists the runs recorded in the journal at path, oldest first. A
+// missing journal has no runs.
+func Read(path string) ([]*Run, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var runs []*Run
+	byId := map[string]*Run{}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("reading journal %s line %d: %w", path, line, err)
+		}
+
+		run, ok := byId[entry.Run]
+		if !ok {
+			run = &Run{Id: entry.Run, Time: entry.Time, Command: entry.Command, Reverts: entry.Reverts}
+			byId[entry.Run] = run
+			runs = append(runs, run)
+		}
+		run.Entries = append(run.Entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, run := range runs {
+		reverted, ok := byId[run.Reverts]
+		if !ok {
+			continue
+		}
+
+		for _, entry := range run.Entries {
+			switch {
+			case entry.RevertsEntry == 0:
+				// journaled before entries were told apart, the undo took them all
+				for i := range reverted.Entries {
+					reverted.Entries[i].RevertedBy = run.Id
+				}
+			case entry.RevertsEntry <= len(reverted.Entries):
+				reverted.Entries[entry.RevertsEntry-1].RevertedBy = run.Id
+			}
+		}
+
+		if !slices.ContainsFunc(reverted.Entries, func(entry Entry) bool { return entry.RevertedBy == "" }) {
+			reverted.RevertedBy = run.Id
+		}
+	}
+
+	return runs, nil
+}
+
+// LastRun is the latest run that neither undid another run nor was undone.
+func LastRun(runs []*Run) (*Run, bool) {
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Reverts == "" && runs[i].RevertedBy == "" {
+			return runs[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// FindRun looks the run id up.
+func FindRun(runs []*Run, id string) (*Run, bool) {
+	for _, run := range runs {
+		if run.Id == id {
+			return run, true
+		}
+	}
+
+	return nil, false
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 
 	"github.com/alinsimion/jira-cli/commands"
 	"github.com/alinsimion/jira-cli/service"
@@ -31,7 +33,11 @@ func main() {
 
 	js := service.NewJiraService(utils.VarMap[utils.JIRA_API_KEY].(string), utils.VarMap[utils.JIRA_ENDPOINT].(string), utils.VarMap[utils.JIRA_USER_EMAIL].(string))
 
+	// Ctrl-C cancels in-flight requests instead of killing the process mid-run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	ce := commands.NewCommandEngine(commands.RootCmd, js)
-	ce.Execute(ce.RootCmd)
+	ce.Execute(ctx, ce.RootCmd)
 
 }
@@ -0,0 +1,172 @@
+// Package period turns the periods accepted on the command line, like
+// "lastweek" or "2024-W32", into concrete ranges of dates.
+package period
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type Period string
+
+const (
+	PeriodDay         string = "day"
+	PeriodWeek        string = "week"
+	PeriodLastWeek    string = "lastweek"
+	PeriodMonth       string = "month"
+	PeriodLastMonth   string = "lastmonth"
+	PeriodQuarter     string = "quarter"
+	PeriodLastQuarter string = "lastquarter"
+)
+
+var (
+	// isoWeek matches an ISO week of the current year, "w32", or of a given one, "2024-W32"
+	isoWeek = regexp.MustCompile(`^(?:(\d{4})-)?[wW](\d{1,2})$`)
+)
+
+func (e *Period) String() string {
+	return string(*e)
+}
+
+func (e *Period) Set(v string) error {
+	if _, err := Resolve(Period(v), time.Now()); err != nil {
+		return err
+	}
+
+	*e = Period(v)
+	return nil
+}
+
+func (e *Period) Type() string {
+	return "Period"
+}
+
+// Range is the span of days from From to To, both included. Both are at
+// midnight in their location.
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// Between builds the range of days from from to to, both included.
+func Between(from time.Time, to time.Time) (Range, error) {
+	r := Range{From: startOfDay(from), To: startOfDay(to)}
+
+	if r.To.Before(r.From) {
+		return Range{}, fmt.Errorf("%s is after %s", r.From.Format(time.DateOnly), r.To.Format(time.DateOnly))
+	}
+
+	return r, nil
+}
+
+// Resolve turns p into the range of days it covers at now. The periods in
+// progress (day, week, month and quarter) end with today, the past ones and
+// ISO weeks are whole.
+func Resolve(p Period, now time.Time) (Range, error) {
+	today := startOfDay(now)
+
+	switch string(p) {
+	case PeriodDay:
+		return Range{From: today, To: today}, nil
+	case PeriodWeek:
+		return Range{From: startOfWeek(today), To: today}, nil
+	case PeriodLastWeek:
+		from := startOfWeek(today).AddDate(0, 0, -7)
+		return Range{From: from, To: from.AddDate(0, 0, 6)}, nil
+	case PeriodMonth:
+		return Range{From: startOfMonth(today), To: today}, nil
+	case PeriodLastMonth:
+		from := startOfMonth(today).AddDate(0, -1, 0)
+		return Range{From: from, To: from.AddDate(0, 1, -1)}, nil
+	case PeriodQuarter:
+		return Range{From: startOfQuarter(today), To: today}, nil
+	case PeriodLastQuarter:
+		from := startOfQuarter(today).AddDate(0, -3, 0)
+		return Range{From: from, To: from.AddDate(0, 3, -1)}, nil
+	}
+
+	if match := isoWeek.FindStringSubmatch(string(p)); match != nil {
+		year := today.Year()
+		if match[1] != "" {
+			year, _ = strconv.Atoi(match[1])
+		}
+		week, _ := strconv.Atoi(match[2])
+
+		return ISOWeek(year, week, today.Location())
+	}
+
+	return Range{}, fmt.Errorf(`must be one of %q, %q, %q, %q, %q, %q, %q or an ISO week like "w32" or "2024-W32"`,
+		PeriodDay, PeriodWeek, PeriodLastWeek, PeriodMonth, PeriodLastMonth, PeriodQuarter, PeriodLastQuarter)
+}
+
+// ISOWeek is the range from Monday to Sunday of the given ISO 8601 week.
+func ISOWeek(year int, week int, loc *time.Location) (Range, error) {
+	// January 4th always falls in the first ISO week of its year
+	from := startOfWeek(time.Date(year, time.January, 4, 0, 0, 0, 0, loc)).AddDate(0, 0, 7*(week-1))
+
+	if isoYear, isoWeek := from.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+		return Range{}, fmt.Errorf("%d has no ISO week %d", year, week)
+	}
+
+	return Range{From: from, To: from.AddDate(0, 0, 6)}, nil
+}
+
+// Month is the whole month of year.
+func Month(year int, month time.Month, loc *time.Location) Range {
+	from := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+
+	return Range{From: from, To: from.AddDate(0, 1, -1)}
+}
+
+// Dates lists every day of the range, in order.
+func (r Range) Dates() []time.Time {
+	var dates []time.Time
+
+	for date := r.From; !date.After(r.To); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
+
+// End is the first moment after the range.
+func (r Range) End() time.Time {
+	return r.To.AddDate(0, 0, 1)
+}
+
+// Contains reports whether t falls on one of the days of the range.
+func (r Range) Contains(t time.Time) bool {
+	return !t.Before(r.From) && t.Before(r.End())
+}
+
+// SingleMonth reports whether the range lies within one calendar month.
+func (r Range) SingleMonth() bool {
+	return r.From.Year() == r.To.Year() && r.From.Month() == r.To.Month()
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("%s - %s", r.From.Format(time.DateOnly), r.To.Format(time.DateOnly))
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek is the Monday of the week of t, weeks start on Monday as in ISO 8601.
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+
+	return startOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func startOfQuarter(t time.Time) time.Time {
+	firstMonth := time.Month((int(t.Month())-1)/3*3 + 1)
+
+	return time.Date(t.Year(), firstMonth, 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,56 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+	// the last day of March, a Sunday, the month after a leap February
+	monthEnd := time.Date(2024, time.March, 31, 18, 0, 0, 0, time.Local)
+	// a Tuesday, early in the year
+	newYear := time.Date(2024, time.January, 2, 9, 0, 0, 0, time.Local)
+	// in the middle of the second quarter
+	midQuarter := time.Date(2024, time.May, 15, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		period  string
+		now     time.Time
+		want    Range
+		wantErr bool
+	}{
+		{name: "day", period: PeriodDay, now: monthEnd, want: Range{date(2024, time.March, 31), date(2024, time.March, 31)}},
+		{name: "week ending on Sunday", period: PeriodWeek, now: monthEnd, want: Range{date(2024, time.March, 25), date(2024, time.March, 31)}},
+		{name: "last week", period: PeriodLastWeek, now: monthEnd, want: Range{date(2024, time.March, 18), date(2024, time.March, 24)}},
+		{name: "month on its last day", period: PeriodMonth, now: monthEnd, want: Range{date(2024, time.March, 1), date(2024, time.March, 31)}},
+		{name: "last month, a leap February", period: PeriodLastMonth, now: monthEnd, want: Range{date(2024, time.February, 1), date(2024, time.February, 29)}},
+		{name: "last week across the new year", period: PeriodLastWeek, now: newYear, want: Range{date(2023, time.December, 25), date(2023, time.December, 31)}},
+		{name: "last month across the new year", period: PeriodLastMonth, now: newYear, want: Range{date(2023, time.December, 1), date(2023, time.December, 31)}},
+		{name: "quarter in progress", period: PeriodQuarter, now: newYear, want: Range{date(2024, time.January, 1), date(2024, time.January, 2)}},
+		{name: "last quarter across the new year", period: PeriodLastQuarter, now: newYear, want: Range{date(2023, time.October, 1), date(2023, time.December, 31)}},
+		{name: "second quarter", period: PeriodQuarter, now: midQuarter, want: Range{date(2024, time.April, 1), date(2024, time.May, 15)}},
+		{name: "first quarter", period: PeriodLastQuarter, now: midQuarter, want: Range{date(2024, time.January, 1), date(2024, time.March, 31)}},
+		{name: "iso week of this year", period: "w1", now: monthEnd, want: Range{date(2024, time.January, 1), date(2024, time.January, 7)}},
+		{name: "iso week starting the year before", period: "2015-W01", now: monthEnd, want: Range{date(2014, time.December, 29), date(2015, time.January, 4)}},
+		{name: "iso week 53", period: "2020-W53", now: monthEnd, want: Range{date(2020, time.December, 28), date(2021, time.January, 3)}},
+		{name: "no iso week 53", period: "2021-W53", now: monthEnd, wantErr: true},
+		{name: "no iso week 0", period: "w0", now: monthEnd, wantErr: true},
+		{name: "unknown period", period: "fortnight", now: monthEnd, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(Period(tt.period), tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+			if !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) {
+				t.Errorf("Resolve(%s) = %s, want %s", tt.period, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package schedule describes the working week of the user: the days work is
+// logged on, the time each of them should have and when worklogs start.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+const (
+	DefaultWeek  = "mon-fri"
+	DefaultStart = "10:00"
+)
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Schedule is a working week. A nil Schedule works from Monday to Friday
+// and starts worklogs at 10:00.
+type Schedule struct {
+	// Days are the working days and the time each should have, 0 for the
+	// days without a time of their own. The days missing are days off.
+	Days map[time.Weekday]time.Duration
+	// Start is the time of day worklogs start at, counted from midnight.
+	Start time.Duration
+}
+
+// Parse reads a working week such as "mon-fri", "sun-thu" or
+// "mon-thu=8,fri=4h 30m", ranges wrapping around the end of the week, and a
+// start time such as "09:30".
+func Parse(week string, start string) (*Schedule, error) {
+	s := &Schedule{Days: map[time.Weekday]time.Duration{}}
+
+	for _, item := range strings.Split(strings.ToLower(week), ",") {
+		days, timeText, hasTime := strings.Cut(strings.TrimSpace(item), "=")
+
+		var workTime time.Duration
+		if hasTime {
+			var err error
+			workTime, err = utils.ParseDuration(timeText)
+			if err != nil || workTime <= 0 || workTime > 24*time.Hour {
+				return nil, fmt.Errorf("work week %q: the time of %s must be a duration between 0 and 24h", week, days)
+			}
+		}
+
+		first, last, isRange := strings.Cut(days, "-")
+		if !isRange {
+			last = first
+		}
+
+		from, err := utils.ParseWeekday(first)
+		if err != nil {
+			return nil, fmt.Errorf("work week %q: %w", week, err)
+		}
+
+		to, err := utils.ParseWeekday(last)
+		if err != nil {
+			return nil, fmt.Errorf("work week %q: %w", week, err)
+		}
+
+		for day := from; ; day = (day + 1) % 7 {
+			if _, ok := s.Days[day]; ok {
+				return nil, fmt.Errorf("work week %q: %s is given twice", week, weekdays[day])
+			}
+			s.Days[day] = workTime
+
+			if day == to {
+				break
+			}
+		}
+	}
+
+	clock, err := time.Parse("15:04", start)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse work start %q, expected hh:mm", start)
+	}
+	s.Start = time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+
+	return s, nil
+}
+
+// IsWorkday reports whether the day of date is a working day.
+func (s *Schedule) IsWorkday(date time.Time) bool {
+	if s == nil {
+		return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+	}
+
+	_, ok := s.Days[date.Weekday()]
+	return ok
+}
+
+// TimeOn is the time the day of date should have, def when the schedule
+// gives it no time of its own.
+func (s *Schedule) TimeOn(date time.Time, def time.Duration) time.Duration {
+	if s == nil || s.Days[date.Weekday()] == 0 {
+		return def
+	}
+
+	return s.Days[date.Weekday()]
+}
+
+// StartOn is when a worklog on the day of date starts.
+func (s *Schedule) StartOn(date time.Time) time.Time {
+	start := 10 * time.Hour
+	if s != nil {
+		start = s.Start
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), int(start/time.Hour), int(start%time.Hour/time.Minute), 0, 0, time.Local)
+}
@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		week      string
+		start     string
+		wantErr   bool
+		wantDays  map[time.Weekday]time.Duration
+		wantStart time.Duration
+	}{
+		{
+			name:  "default",
+			week:  DefaultWeek,
+			start: DefaultStart,
+			wantDays: map[time.Weekday]time.Duration{
+				time.Monday: 0, time.Tuesday: 0, time.Wednesday: 0, time.Thursday: 0, time.Friday: 0,
+			},
+			wantStart: 10 * time.Hour,
+		},
+		{
+			name:  "wrapping range",
+			week:  "fri-mon",
+			start: "09:30",
+			wantDays: map[time.Weekday]time.Duration{
+				time.Friday: 0, time.Saturday: 0, time.Sunday: 0, time.Monday: 0,
+			},
+			wantStart: 9*time.Hour + 30*time.Minute,
+		},
+		{
+			name:  "hours per day",
+			week:  "Mon-Thu=8, fri=4h 30m",
+			start: "08:00",
+			wantDays: map[time.Weekday]time.Duration{
+				time.Monday: 8 * time.Hour, time.Tuesday: 8 * time.Hour, time.Wednesday: 8 * time.Hour, time.Thursday: 8 * time.Hour,
+				time.Friday: 4*time.Hour + 30*time.Minute,
+			},
+			wantStart: 8 * time.Hour,
+		},
+		{
+			name:      "single days",
+			week:      "tue,thu=6",
+			start:     "10:00",
+			wantDays:  map[time.Weekday]time.Duration{time.Tuesday: 0, time.Thursday: 6 * time.Hour},
+			wantStart: 10 * time.Hour,
+		},
+		{name: "unknown day", week: "mon-fry", start: "10:00", wantErr: true},
+		{name: "day given twice", week: "mon-fri,wed=4", start: "10:00", wantErr: true},
+		{name: "whole week wrapping onto itself", week: "mon-sun,mon", start: "10:00", wantErr: true},
+		{name: "no time", week: "mon-fri=0", start: "10:00", wantErr: true},
+		{name: "more than a day", week: "mon=25h", start: "10:00", wantErr: true},
+		{name: "bad time", week: "mon=lots", start: "10:00", wantErr: true},
+		{name: "bad start", week: "mon-fri", start: "9am", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.week, tt.start)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !maps.Equal(got.Days, tt.wantDays) {
+				t.Errorf("got days %v, want %v", got.Days, tt.wantDays)
+			}
+			if got.Start != tt.wantStart {
+				t.Errorf("got start %s, want %s", got.Start, tt.wantStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in recorded headers.
+const redacted = "REDACTED"
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// volatileParams are query parameters worth nothing to replay, the bounds of
+// relative periods move with the clock between recording and replaying.
+var volatileParams = []string{"startedAfter", "startedBefore"}
+
+// Interaction is one request to Jira and the response it got, as stored in a
+// cassette file.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// recorder is a RoundTripper saving every interaction into its own cassette
+// file in dir, numbered in the order the requests were sent.
+type recorder struct {
+	next http.RoundTripper
+	dir  string
+
+	mu  sync.Mutex
+	seq int
+}
+
+func (rec *recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := peekRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := rec.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := peekResponseBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    request.URL.RequestURI(),
+			Header: redactHeader(request.Header),
+			Body:   string(requestBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     redactHeader(response.Header),
+			Body:       string(responseBody),
+		},
+	}
+
+	// the response got to us fine, a cassette missing must not fail the run
+	if err := rec.save(interaction); err != nil {
+		slog.Error("could not save the cassette", "dir", rec.dir, "url", interaction.Request.URL, "error", err.Error())
+	}
+
+	return response, nil
+}
+
+func (rec *recorder) save(interaction Interaction) error {
+	rec.mu.Lock()
+	rec.seq++
+	seq := rec.seq
+	rec.mu.Unlock()
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(rec.dir, 0o755); err != nil {
+		return err
+	}
+
+	path := strings.Trim(unsafePathChars.ReplaceAllString(strings.SplitN(interaction.Request.URL, "?", 2)[0], "_"), "_")
+	name := fmt.Sprintf("%04d-%s-%s.json", seq, interaction.Request.Method, path)
+
+	return os.WriteFile(filepath.Join(rec.dir, name), data, 0o644)
+}
+
+// replayer is a RoundTripper answering requests from the cassette files in
+// dir instead of the network. Each recorded interaction is served once, the
+// first unused one with the same method, path and query wins, preferring
+// one whose body matches too. The volatileParams of the query are left out
+// of the match, so the interactions sharing the rest are served in the
+// order they were recorded.
+type replayer struct {
+	dir string
+
+	once         sync.Once
+	loadErr      error
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func (rep *replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	rep.once.Do(rep.load)
+	if rep.loadErr != nil {
+		return nil, rep.loadErr
+	}
+
+	requestBody, err := peekRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	uri := replayURI(request.URL.RequestURI())
+
+	found := -1
+	for i, interaction := range rep.interactions {
+		if rep.used[i] || interaction.Request.Method != request.Method || replayURI(interaction.Request.URL) != uri {
+			continue
+		}
+
+		if interaction.Request.Body == string(requestBody) {
+			found = i
+			break
+		}
+
+		if found == -1 {
+			found = i
+		}
+	}
+
+	if found == -1 {
+		return nil, fmt.Errorf("no recorded response in %s for %s %s", rep.dir, request.Method, request.URL.RequestURI())
+	}
+
+	rep.used[found] = true
+	recorded := rep.interactions[found].Response
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       request,
+	}, nil
+}
+
+func (rep *replayer) load() {
+	files, err := filepath.Glob(filepath.Join(rep.dir, "*.json"))
+	if err != nil {
+		rep.loadErr = err
+		return
+	}
+
+	if len(files) == 0 {
+		rep.loadErr = fmt.Errorf("no cassette files found in %s", rep.dir)
+		return
+	}
+
+	// the sequence number prefix keeps the recording order
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			rep.loadErr = err
+			return
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			rep.loadErr = fmt.Errorf("reading cassette %s: %w", file, err)
+			return
+		}
+
+		rep.interactions = append(rep.interactions, interaction)
+	}
+
+	rep.used = make([]bool, len(rep.interactions))
+}
+
+// replayURI is uri without its volatileParams, the rest of the query sorted.
+func replayURI(uri string) string {
+	parsed, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return uri
+	}
+
+	query := parsed.Query()
+	for _, name := range volatileParams {
+		query.Del(name)
+	}
+
+	if len(query) == 0 {
+		return parsed.EscapedPath()
+	}
+
+	return parsed.EscapedPath() + "?" + query.Encode()
+}
+
+// peekRequestBody returns the body of request without consuming it.
+func peekRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.GetBody == nil {
+		return nil, nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// peekResponseBody reads the body of response and puts it back for the caller.
+func peekResponseBody(response *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// redactHeader copies header without credentials.
+func redactHeader(header http.Header) http.Header {
+	clean := header.Clone()
+
+	for _, name := range []string{"Authorization", "Cookie", "Set-Cookie"} {
+		if clean.Get(name) != "" {
+			clean.Set(name, redacted)
+		}
+	}
+
+	return clean
+}
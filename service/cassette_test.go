@@ -0,0 +1,124 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// record sends the GET requests of uris to a server answering with their
+// query, through a recorder saving into dir.
+func record(t *testing.T, dir string, uris ...string) {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RawQuery))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &recorder{next: http.DefaultTransport, dir: dir}}
+	for _, uri := range uris {
+		response, err := client.Get(srv.URL + uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded []string
+		replayed string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "same query",
+			recorded: []string{"/rest/api/3/issue/GAIA-1/worklog?startAt=0"},
+			replayed: "/rest/api/3/issue/GAIA-1/worklog?startAt=0",
+			want:     "startAt=0",
+		},
+		{
+			// last week starts on another day once the week is over
+			name:     "moved period",
+			recorded: []string{"/rest/api/3/issue/GAIA-1/worklog?startAt=0&startedAfter=1709506800000&startedBefore=1710111600000"},
+			replayed: "/rest/api/3/issue/GAIA-1/worklog?startAt=0&startedAfter=1710111600000&startedBefore=1710716400000",
+			want:     "startAt=0&startedAfter=1709506800000&startedBefore=1710111600000",
+		},
+		{
+			name: "recorded order",
+			recorded: []string{
+				"/rest/api/3/issue/GAIA-1/worklog?startedAfter=1",
+				"/rest/api/3/issue/GAIA-1/worklog?startedAfter=2",
+			},
+			replayed: "/rest/api/3/issue/GAIA-1/worklog?startedAfter=3",
+			want:     "startedAfter=1",
+		},
+		{
+			name:     "other query",
+			recorded: []string{"/rest/api/3/issue/GAIA-1/worklog?startAt=0"},
+			replayed: "/rest/api/3/issue/GAIA-1/worklog?startAt=1000",
+			wantErr:  true,
+		},
+		{
+			name:     "other path",
+			recorded: []string{"/rest/api/3/issue/GAIA-1/worklog"},
+			replayed: "/rest/api/3/issue/GAIA-2/worklog",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			record(t, dir, tt.recorded...)
+
+			client := &http.Client{Transport: &replayer{dir: dir}}
+			response, err := client.Get("http://jira.example.com" + tt.replayed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer response.Body.Close()
+
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("replayed %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorderSaveFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	// a file where the cassettes directory should be
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &recorder{next: http.DefaultTransport, dir: dir}}
+	response, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("got error %v, want the response", err)
+	}
+	defer response.Body.Close()
+
+	if body, _ := io.ReadAll(response.Body); string(body) != "{}" {
+		t.Errorf("got body %q, want {}", body)
+	}
+}
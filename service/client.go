@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+// JiraClient is the set of Jira operations the commands are built on.
+// JiraService talks to a real Jira instance, FakeJiraService keeps
+// everything in memory.
+type JiraClient interface {
+	LogWork(ctx context.Context, params utils.LogWorkParams) error
+	LogWorkMulti(ctx context.Context, params utils.LogWorkParams) error
+	PlanFill(ctx context.Context, params utils.FillParams) ([]utils.LogWorkParams, error)
+	GetIssues(ctx context.Context, jql string) ([]Issue, error)
+	GetUsersIssuesFromPeriod(ctx context.Context, start time.Time, end time.Time) ([]Issue, error)
+	GetUserWorkLogs(ctx context.Context, days period.Range) (map[string]map[string][]string, error)
+	GetTimeTracking(ctx context.Context, issue string) (TimeTracking, error)
+	GetWorkLogsForIssue(ctx context.Context, issue string, query WorklogQuery) (WorklogsResponseObject, error)
+	GetWorklog(ctx context.Context, id string) (WorklogResponseObject, error)
+	FindWorklogs(ctx context.Context, issue string, days period.Range) ([]WorklogResponseObject, error)
+	UpdateWorklog(ctx context.Context, issue string, id string, update WorklogUpdate) error
+	DeleteWorklog(ctx context.Context, issue string, id string, adjust DeleteAdjustment) error
+	GetMySelf(ctx context.Context) error
+	UpdateIssue(ctx context.Context, issue string, status string) error
+}
+
+var (
+	_ JiraClient = (*JiraService)(nil)
+	_ JiraClient = (*FakeJiraService)(nil)
+)
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/alinsimion/jira-cli/absence"
+	"github.com/alinsimion/jira-cli/holidays"
+	"github.com/alinsimion/jira-cli/journal"
+	"github.com/alinsimion/jira-cli/schedule"
+)
+
+const (
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultReadTimeout    = 30 * time.Second
+	DefaultMaxAttempts    = 4
+	DefaultConcurrency    = 4
+)
+
+// Config tunes how JiraService talks to Jira.
+type Config struct {
+	// ConnectTimeout bounds dialing and the TLS handshake.
+	ConnectTimeout time.Duration
+	// ReadTimeout bounds the wait for Jira's response once the request is sent.
+	ReadTimeout time.Duration
+	// MaxAttempts is how many times a rate limited or failed request is sent before giving up.
+	MaxAttempts int
+	// Concurrency is how many issues have their worklogs fetched at the same time.
+	Concurrency int
+	// Deployment is DeploymentCloud, DeploymentServer (Server and Data Center)
+	// or DeploymentAuto to ask Jira.
+	Deployment string
+	// Auth is AuthBasic (email and API token), AuthBearer (personal access
+	// token) or AuthAuto to use basic auth whenever an email is set.
+	Auth string
+	// RecordDir, when set, is where every request and its response are saved
+	// as cassette files.
+	RecordDir string
+	// ReplayDir, when set, is where responses are served from instead of Jira.
+	ReplayDir string
+	// Debug logs every request and response.
+	Debug bool
+	// Schedule is the working week, the days work is logged on, their hours
+	// and when worklogs start.
+	Schedule *schedule.Schedule
+	// Holidays are the days work is never logged on when logging a range of days.
+	Holidays *holidays.Calendar
+	// Absences are the days off of the user, booked on AbsenceIssue when it
+	// is set and skipped otherwise when logging a range of days.
+	Absences     *absence.Store
+	AbsenceIssue string
+	// Preview decides whether the changes of mutating calls are sent to Jira.
+	Preview *Preview
+	// Journal records the changes sent to Jira so they can be undone.
+	Journal *journal.Journal
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ConnectTimeout: DefaultConnectTimeout,
+		ReadTimeout:    DefaultReadTimeout,
+		MaxAttempts:    DefaultMaxAttempts,
+		Concurrency:    DefaultConcurrency,
+		Deployment:     DeploymentAuto,
+		Auth:           AuthAuto,
+	}
+}
+
+func (cfg Config) Validate() error {
+	switch cfg.Deployment {
+	case DeploymentAuto, DeploymentCloud, DeploymentServer:
+	default:
+		return fmt.Errorf("deployment must be one of %s", []string{DeploymentAuto, DeploymentCloud, DeploymentServer})
+	}
+
+	switch cfg.Auth {
+	case AuthAuto, AuthBasic, AuthBearer:
+	default:
+		return fmt.Errorf("auth must be one of %s", []string{AuthAuto, AuthBasic, AuthBearer})
+	}
+
+	if cfg.RecordDir != "" && cfg.ReplayDir != "" {
+		return errors.New("cannot record and replay at the same time")
+	}
+
+	return nil
+}
+
+// Configurable is implemented by clients whose transport and days off can be
+// tuned from the command line.
+type Configurable interface {
+	Configure(cfg Config)
+}
+
+func (cfg Config) httpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = cfg.ConnectTimeout
+	transport.ResponseHeaderTimeout = cfg.ReadTimeout
+
+	var roundTripper http.RoundTripper = transport
+
+	if cfg.RecordDir != "" {
+		roundTripper = &recorder{next: roundTripper, dir: cfg.RecordDir}
+	}
+
+	if cfg.ReplayDir != "" {
+		roundTripper = &replayer{dir: cfg.ReplayDir}
+	}
+
+	if cfg.Debug {
+		roundTripper = &debugTransport{next: roundTripper}
+	}
+
+	return &http.Client{Transport: roundTripper}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// maxLoggedBody caps how much of a body is logged in debug mode.
+const maxLoggedBody = 2048
+
+var sensitiveFields = regexp.MustCompile(`("(?:emailAddress|password|token|apiToken|accessToken)"\s*:\s*)"[^"]*"`)
+
+var (
+	requests     atomic.Int64
+	requestsTime atomic.Int64
+)
+
+// Usage reports how many requests were sent to Jira so far, retries
+// included, and the time spent waiting for them.
+func Usage() (int64, time.Duration) {
+	return requests.Load(), time.Duration(requestsTime.Load())
+}
+
+// countRequest adds a request that took elapsed to the usage statistics.
+func countRequest(elapsed time.Duration) {
+	requests.Add(1)
+	requestsTime.Add(int64(elapsed))
+}
+
+// debugTransport logs every request and response going through it.
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (dt *debugTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := peekRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("jira request", "method", request.Method, "url", request.URL.String(), "header", redactHeader(request.Header), "body", redactBody(requestBody))
+
+	start := time.Now()
+	response, err := dt.next.RoundTrip(request)
+	latency := time.Since(start)
+
+	if err != nil {
+		slog.Debug("jira request failed", "method", request.Method, "url", request.URL.String(), "latency", latency, "error", err.Error())
+		return nil, err
+	}
+
+	responseBody, err := peekResponseBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("jira response", "method", request.Method, "url", request.URL.String(), "status", response.StatusCode, "latency", latency, "body", redactBody(responseBody))
+
+	return response, nil
+}
+
+// redactBody hides credentials and email addresses and truncates long bodies.
+func redactBody(body []byte) string {
+	clean := sensitiveFields.ReplaceAllString(string(body), `$1"`+redacted+`"`)
+
+	if len(clean) > maxLoggedBody {
+		return clean[:maxLoggedBody] + "...(truncated)"
+	}
+
+	return clean
+}
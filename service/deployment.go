@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/alinsimion/jira-cli/adf"
+)
+
+const (
+	DeploymentAuto   = "auto"
+	DeploymentCloud  = "cloud"
+	DeploymentServer = "server"
+
+	AuthAuto   = "auto"
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+type serverInfo struct {
+	DeploymentType string `json:"deploymentType"`
+	Version        string `json:"version"`
+}
+
+// deployment tells whether js talks to Jira Cloud or to Jira Server / Data
+// Center. With DeploymentAuto it asks /serverInfo the first time it is needed.
+func (js *JiraService) deployment(ctx context.Context) string {
+	if js.cfg.Deployment != "" && js.cfg.Deployment != DeploymentAuto {
+		return js.cfg.Deployment
+	}
+
+	js.detect.Do(func() {
+		js.detected = DeploymentCloud
+
+		var info serverInfo
+
+		// serverInfo lives under v2 on every deployment
+		err := js.Do(ctx, Request{Method: http.MethodGet, Path: "rest/api/2/serverInfo"}, &info)
+		if err != nil {
+			slog.Warn("could not detect jira deployment, assuming cloud", "error", err.Error())
+			return
+		}
+
+		if !strings.EqualFold(info.DeploymentType, "Cloud") {
+			js.detected = DeploymentServer
+		}
+
+		slog.Debug("detected jira deployment", "deploymentType", info.DeploymentType, "version", info.Version)
+	})
+
+	return js.detected
+}
+
+func (js *JiraService) isCloud(ctx context.Context) bool {
+	return js.deployment(ctx) == DeploymentCloud
+}
+
+// api builds the path of a REST resource, i.e "issue/X-1/worklog", for the
+// API version of the deployment: v3 on Cloud, v2 on Server.
+func (js *JiraService) api(ctx context.Context, resource string) string {
+	if js.isCloud(ctx) {
+		return "rest/api/3/" + resource
+	}
+
+	return "rest/api/2/" + resource
+}
+
+// comment renders text, written in Markdown, the way the deployment expects
+// worklog comments: an Atlassian Document on Cloud and the text as it is on
+// Server.
+func (js *JiraService) comment(ctx context.Context, text string) any {
+	if !js.isCloud(ctx) {
+		return text
+	}
+
+	return adf.FromMarkdown(text)
+}
+
+// commentText is the plain text of a worklog comment, whether it came as an
+// Atlassian Document or as text. The blocks of a document go on lines of
+// their own.
+func commentText(comment any) string {
+	switch comment := comment.(type) {
+	case string:
+		return comment
+	case map[string]any:
+		if comment["type"] == "hardBreak" {
+			return "\n"
+		}
+
+		text, _ := comment["text"].(string)
+		var content []any
+		switch nodes := comment["content"].(type) {
+		case []any:
+			content = nodes
+		case []adf.Node:
+			for _, node := range nodes {
+				content = append(content, node)
+			}
+		}
+		for i, node := range content {
+			if i > 0 && blockContainers[comment["type"]] {
+				text += "\n"
+			}
+			text += commentText(node)
+		}
+		return text
+	default:
+		return ""
+	}
+}
+
+// blockContainers are the document nodes holding blocks rather than text.
+var blockContainers = map[any]bool{
+	"doc":         true,
+	"bulletList":  true,
+	"orderedList": true,
+	"listItem":    true,
+	"blockquote":  true,
+	"panel":       true,
+}
+
+// baseURL is the Jira endpoint with its scheme, https unless one is given.
+func (js *JiraService) baseURL() string {
+	endpoint := strings.TrimSuffix(js.Endpoint, "/")
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+
+	return "https://" + endpoint
+}
+
+// authorize signs request with an email and API token pair (Cloud) or with
+// a personal access token (Server / Data Center).
+func (js *JiraService) authorize(request *http.Request) {
+	auth := js.cfg.Auth
+	if auth == "" || auth == AuthAuto {
+		auth = AuthBasic
+		if js.Email == "" {
+			auth = AuthBearer
+		}
+	}
+
+	if auth == AuthBearer {
+		request.Header.Set("Authorization", "Bearer "+js.APIToken)
+		return
+	}
+
+	request.SetBasicAuth(js.Email, js.APIToken)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// JiraError is returned by every service call Jira answered with a non 2xx status.
+type JiraError struct {
+	StatusCode    int
+	Method        string
+	Path          string
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+func (e *JiraError) Error() string {
+	messages := append([]string{}, e.ErrorMessages...)
+
+	fields := make([]string, 0, len(e.Errors))
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, e.Errors[field]))
+	}
+
+	if len(messages) == 0 {
+		messages = append(messages, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, strings.Join(messages, ", "))
+}
+
+// Is lets errors.Is match a JiraError against ErrUnauthorized, ErrForbidden,
+// ErrNotFound and ErrRateLimited.
+func (e *JiraError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// decodeJiraResponse reads and closes the response body. A 2xx body is
+// unmarshaled into out when out is not nil, anything else becomes a *JiraError.
+func decodeJiraResponse(response *http.Response, out any) error {
+	defer response.Body.Close()
+
+	readData, err := io.ReadAll(response.Body)
+	if err != nil {
+		slog.Error("Error while reading response from jira", "error", err.Error())
+		return err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		jiraError := &JiraError{
+			StatusCode: response.StatusCode,
+			Method:     response.Request.Method,
+			Path:       response.Request.URL.Path,
+		}
+
+		// the body is only a hint, a proxy may well answer with html
+		json.Unmarshal(readData, jiraError)
+
+		return jiraError
+	}
+
+	if out == nil || len(readData) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(readData, out)
+	if err != nil {
+		slog.Error("Error while unmarshaling jira response", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
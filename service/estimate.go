@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+// TimeTracking is the timetracking field of an issue. Jira leaves it empty
+// for issues that were never estimated.
+type TimeTracking struct {
+	OriginalEstimate         string `json:"originalEstimate"`
+	RemainingEstimate        string `json:"remainingEstimate"`
+	TimeSpent                string `json:"timeSpent"`
+	OriginalEstimateSeconds  int    `json:"originalEstimateSeconds"`
+	RemainingEstimateSeconds int    `json:"remainingEstimateSeconds"`
+	TimeSpentSeconds         int    `json:"timeSpentSeconds"`
+}
+
+// Estimated reports whether the issue has an estimate to go over.
+func (tt TimeTracking) Estimated() bool {
+	return tt.OriginalEstimate != "" || tt.RemainingEstimate != ""
+}
+
+func (tt TimeTracking) Remaining() time.Duration {
+	return time.Duration(tt.RemainingEstimateSeconds) * time.Second
+}
+
+func (tt TimeTracking) String() string {
+	return fmt.Sprintf("original %s, remaining %s, spent %s",
+		utils.FormatDuration(time.Duration(tt.OriginalEstimateSeconds)*time.Second),
+		utils.FormatDuration(tt.Remaining()),
+		utils.FormatDuration(time.Duration(tt.TimeSpentSeconds)*time.Second))
+}
+
+// estimateValues are the query parameters of a worklog post adjusting the
+// remaining estimate the way params asks, none for Jira's default.
+func estimateValues(params utils.LogWorkParams) url.Values {
+	values := url.Values{}
+	if params.AdjustEstimate.IsAuto() {
+		return values
+	}
+
+	values.Set("adjustEstimate", string(params.AdjustEstimate))
+
+	switch string(params.AdjustEstimate) {
+	case utils.AdjustEstimateNew:
+		values.Set("newEstimate", estimateText(*params.NewEstimate))
+	case utils.AdjustEstimateManual:
+		values.Set("reduceBy", estimateText(params.ReduceBy))
+	}
+
+	return values
+}
+
+// DeleteAdjustment tells Jira what to do with the remaining estimate of an
+// issue when one of its worklogs is deleted. The zero value gives the time of
+// the worklog back to it, Jira's default.
+type DeleteAdjustment struct {
+	AdjustEstimate utils.AdjustEstimate
+	// IncreaseBy is how much to give back with AdjustEstimateManual
+	IncreaseBy time.Duration
+}
+
+// values are the query parameters of a worklog delete adjusting the remaining
+// estimate the way da asks, none for Jira's default.
+func (da DeleteAdjustment) values() url.Values {
+	values := url.Values{}
+	if da.AdjustEstimate.IsAuto() {
+		return values
+	}
+
+	values.Set("adjustEstimate", string(da.AdjustEstimate))
+	if string(da.AdjustEstimate) == utils.AdjustEstimateManual {
+		values.Set("increaseBy", estimateText(da.IncreaseBy))
+	}
+
+	return values
+}
+
+// estimateText writes d in minutes, which Jira reads the same whatever the
+// length of its days and weeks.
+func estimateText(d time.Duration) string {
+	return fmt.Sprintf("%dm", d.Round(time.Minute)/time.Minute)
+}
+
+// checkEstimate warns when the worklog of params takes more off the
+// remaining estimate of its issue than is left, or refuses it when
+// params.Strict. That is the time spent, or params.ReduceBy when adjusting
+// manually. Leaving the estimate or setting a new one leaves nothing to go
+// over. An estimate that cannot be fetched only stops strict runs.
+func checkEstimate(ctx context.Context, client JiraClient, params utils.LogWorkParams) error {
+	reduction, what := params.TimeSpent, fmt.Sprintf("%s of work on %s", utils.FormatDuration(params.TimeSpent), params.IssueKey)
+
+	switch string(params.AdjustEstimate) {
+	case utils.AdjustEstimateLeave, utils.AdjustEstimateNew:
+		return nil
+	case utils.AdjustEstimateManual:
+		reduction, what = params.ReduceBy, fmt.Sprintf("--reduce-by %s on %s", utils.FormatDuration(params.ReduceBy), params.IssueKey)
+	}
+
+	tracking, err := client.GetTimeTracking(ctx, params.IssueKey)
+	if err != nil {
+		if params.Strict {
+			return fmt.Errorf("could not check the remaining estimate of %s: %w", params.IssueKey, err)
+		}
+		slog.Warn("could not check the remaining estimate", "issue", params.IssueKey, "error", err.Error())
+		return nil
+	}
+
+	if !tracking.Estimated() || reduction <= tracking.Remaining() {
+		return nil
+	}
+
+	if params.Strict {
+		return fmt.Errorf("%s goes over its remaining estimate (%s), leave out --strict to log it anyway", what, tracking)
+	}
+
+	slog.Warn("the worklog goes over the remaining estimate", "issue", params.IssueKey, "reduction", utils.FormatDuration(reduction), "original", utils.FormatDuration(time.Duration(tracking.OriginalEstimateSeconds)*time.Second), "remaining", utils.FormatDuration(tracking.Remaining()), "spent", utils.FormatDuration(time.Duration(tracking.TimeSpentSeconds)*time.Second))
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+func TestCheckEstimate(t *testing.T) {
+	newEstimate := time.Duration(0)
+
+	tests := []struct {
+		name    string
+		params  utils.LogWorkParams
+		wantErr bool
+	}{
+		{
+			name:   "within the estimate",
+			params: utils.LogWorkParams{TimeSpent: 2 * time.Hour},
+		},
+		{
+			name:    "over the estimate",
+			params:  utils.LogWorkParams{TimeSpent: 4 * time.Hour},
+			wantErr: true,
+		},
+		{
+			name:   "estimate left alone",
+			params: utils.LogWorkParams{TimeSpent: 4 * time.Hour, AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateLeave)},
+		},
+		{
+			name:   "new estimate",
+			params: utils.LogWorkParams{TimeSpent: 4 * time.Hour, AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateNew), NewEstimate: &newEstimate},
+		},
+		{
+			name:   "reduced by less than left",
+			params: utils.LogWorkParams{TimeSpent: 4 * time.Hour, AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateManual), ReduceBy: time.Hour},
+		},
+		{
+			name:    "reduced by more than left",
+			params:  utils.LogWorkParams{TimeSpent: time.Hour, AdjustEstimate: utils.AdjustEstimate(utils.AdjustEstimateManual), ReduceBy: 4 * time.Hour},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewFakeJiraService(JiraUser{Name: "jdoe"}, Issue{Key: "GAIA-1"})
+			fs.SetTimeTracking("GAIA-1", TimeTracking{OriginalEstimate: "3h", RemainingEstimate: "3h", OriginalEstimateSeconds: 10800, RemainingEstimateSeconds: 10800})
+
+			tt.params.IssueKey = "GAIA-1"
+			tt.params.Strict = true
+
+			err := checkEstimate(context.Background(), fs, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want one: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
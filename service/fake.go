@@ -0,0 +1,492 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alinsimion/jira-cli/adf"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+// DefaultStatus is the status the issues of FakeJiraService start in, the
+// first one of Jira's default workflow.
+const DefaultStatus = "To Do"
+
+// FakeJiraService is an in-memory JiraClient. It stores issues and the
+// worklogs booked on them so commands can be exercised without a Jira
+// instance.
+type FakeJiraService struct {
+	User JiraUser
+
+	cfg Config
+
+	mu        sync.Mutex
+	issues    []Issue
+	statuses  map[string]string
+	tracking  map[string]TimeTracking
+	nextLogId int
+}
+
+func NewFakeJiraService(user JiraUser, issues ...Issue) *FakeJiraService {
+	fs := &FakeJiraService{
+		User:      user,
+		cfg:       DefaultConfig(),
+		statuses:  map[string]string{},
+		tracking:  map[string]TimeTracking{},
+		nextLogId: 1,
+	}
+
+	for _, issue := range issues {
+		fs.AddIssue(issue)
+	}
+
+	return fs
+}
+
+// AddIssue stores issue, replacing any issue with the same key. Its
+// worklogs are told the id of the issue when they miss it.
+func (fs *FakeJiraService) AddIssue(issue Issue) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if issue.Id == "" {
+		issue.Id = strconv.Itoa(10000 + len(fs.issues))
+	}
+
+	issue.Worklogs = append([]WorklogResponseObject{}, issue.Worklogs...)
+	for i := range issue.Worklogs {
+		if issue.Worklogs[i].IssueId == "" {
+			issue.Worklogs[i].IssueId = issue.Id
+		}
+	}
+
+	for i := range fs.issues {
+		if fs.issues[i].Key == issue.Key {
+			fs.issues[i] = issue
+			return
+		}
+	}
+
+	fs.issues = append(fs.issues, issue)
+}
+
+// Configure keeps cfg for the days off and the concurrency, the transport
+// settings mean nothing without Jira.
+func (fs *FakeJiraService) Configure(cfg Config) {
+	fs.cfg = cfg
+}
+
+// SetTimeTracking estimates issue. Worklogs logged on it afterwards adjust
+// the remaining estimate the way Jira does.
+func (fs *FakeJiraService) SetTimeTracking(issue string, tracking TimeTracking) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.tracking[issue] = tracking
+}
+
+// Status returns the status issue was last moved to with UpdateIssue,
+// DefaultStatus when it never was.
+func (fs *FakeJiraService) Status(issue string) string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.status(issue)
+}
+
+// status is the status of the issue key. fs.mu must be held.
+func (fs *FakeJiraService) status(key string) string {
+	if status, ok := fs.statuses[key]; ok {
+		return status
+	}
+
+	return DefaultStatus
+}
+
+func (fs *FakeJiraService) LogWork(ctx context.Context, params utils.LogWorkParams) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tempDate, err := workLogDate(params, fs.cfg.Schedule)
+	if err != nil {
+		return err
+	}
+
+	if err := checkWorkday(params, tempDate, fs.cfg.Schedule); err != nil {
+		return err
+	}
+
+	// before the preview, so a dry run shows the worklogs going over too
+	if err := checkEstimate(ctx, fs, params); err != nil {
+		return err
+	}
+
+	seconds := params.Seconds()
+	change := Change{
+		Action:           ActionLogWork,
+		Issue:            params.IssueKey,
+		Started:          tempDate,
+		TimeSpentSeconds: seconds,
+		Comment:          params.Message,
+		AdjustEstimate:   params.AdjustEstimate,
+		AdjustBy:         params.ReduceBy,
+	}
+	if !params.Visibility.IsZero() {
+		visibility := params.Visibility
+		change.Visibility = &visibility
+	}
+	if !fs.cfg.Preview.Approve(change) {
+		return nil
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	issue := fs.issue(params.IssueKey)
+	if issue == nil {
+		return issueNotFound("POST", params.IssueKey)
+	}
+
+	worklog := WorklogResponseObject{
+		Author:           fs.User,
+		Comment:          adf.FromMarkdown(params.Message),
+		Id:               strconv.Itoa(fs.nextLogId),
+		IssueId:          issue.Id,
+		TimeSpent:        formatTimeSpent(seconds),
+		TimeSpentSeconds: float64(seconds),
+		UpdateAuthor:     fs.User,
+		Started:          utils.CustomTime{Time: tempDate},
+		Updated:          utils.CustomTime{Time: time.Now()},
+		Created:          utils.CustomTime{Time: time.Now()},
+	}
+	if !params.Visibility.IsZero() {
+		visibility := params.Visibility
+		worklog.Visibility = &visibility
+	}
+	fs.nextLogId++
+
+	if tracking, ok := fs.tracking[issue.Key]; ok {
+		fs.tracking[issue.Key] = tracking.logged(params)
+	}
+
+	issue.Worklogs = append(issue.Worklogs, worklog)
+	issue.Updated = time.Now()
+
+	recordChange(fs.cfg.Journal, change, worklog.Id, nil)
+
+	return nil
+}
+
+func (fs *FakeJiraService) LogWorkMulti(ctx context.Context, params utils.LogWorkParams) error {
+	return logWorkMulti(ctx, fs, params, fs.cfg, fs.User)
+}
+
+func (fs *FakeJiraService) PlanFill(ctx context.Context, params utils.FillParams) ([]utils.LogWorkParams, error) {
+	return planFill(ctx, fs, params, fs.cfg, fs.User)
+}
+
+// GetIssues ignores jql and returns every stored issue.
+func (fs *FakeJiraService) GetIssues(ctx context.Context, jql string) ([]Issue, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	issues := make([]Issue, len(fs.issues))
+	for i, issue := range fs.issues {
+		issues[i] = issue
+		issues[i].Worklogs = nil
+	}
+
+	return issues, nil
+}
+
+// GetUsersIssuesFromPeriod returns the issues the user logged work on from
+// the day of start to the day of end, both included.
+func (fs *FakeJiraService) GetUsersIssuesFromPeriod(ctx context.Context, start time.Time, end time.Time) ([]Issue, error) {
+	days, err := period.Between(start, end)
+	if err != nil {
+		return []Issue{}, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	issues := []Issue{}
+	for _, issue := range fs.issues {
+		for _, worklog := range issue.Worklogs {
+			if authoredBy(worklog, fs.User) && days.Contains(worklog.Started.Time) {
+				issue.Worklogs = nil
+				issues = append(issues, issue)
+				break
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+func (fs *FakeJiraService) GetUserWorkLogs(ctx context.Context, days period.Range) (map[string]map[string][]string, error) {
+	usersIssues, err := fs.GetUsersIssuesFromPeriod(ctx, days.From, days.To)
+	if err != nil {
+		return map[string]map[string][]string{}, err
+	}
+
+	err = fetchIssueWorklogs(ctx, fs, usersIssues, rangeQuery(days), fs.cfg.Concurrency)
+
+	return worklogTable(usersIssues, days, fs.cfg), err
+}
+
+func (fs *FakeJiraService) GetWorkLogsForIssue(ctx context.Context, issue string, query WorklogQuery) (WorklogsResponseObject, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	stored := fs.issue(issue)
+	if stored == nil {
+		return WorklogsResponseObject{}, issueNotFound("GET", issue)
+	}
+
+	worklogs := []WorklogResponseObject{}
+	for _, worklog := range stored.Worklogs {
+		if query.contains(worklog.Started.Time) {
+			worklogs = append(worklogs, worklog)
+		}
+	}
+
+	return WorklogsResponseObject{
+		MaxResults: len(worklogs),
+		Total:      len(worklogs),
+		WorkLogs:   worklogs,
+	}, nil
+}
+
+func (fs *FakeJiraService) GetTimeTracking(ctx context.Context, issue string) (TimeTracking, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	stored := fs.issue(issue)
+	if stored == nil {
+		return TimeTracking{}, issueNotFound("GET", issue)
+	}
+
+	return fs.tracking[stored.Key], nil
+}
+
+func (fs *FakeJiraService) GetWorklog(ctx context.Context, id string) (WorklogResponseObject, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, worklog := fs.worklog(id); worklog != nil {
+		return *worklog, nil
+	}
+
+	return WorklogResponseObject{}, fmt.Errorf("worklog %s: %w", id, ErrNotFound)
+}
+
+func (fs *FakeJiraService) FindWorklogs(ctx context.Context, issue string, days period.Range) ([]WorklogResponseObject, error) {
+	return findWorklogs(ctx, fs, fs.User, issue, days)
+}
+
+// UpdateWorklog changes the worklog id of issue, issue being its key or its id.
+func (fs *FakeJiraService) UpdateWorklog(ctx context.Context, issue string, id string, update WorklogUpdate) error {
+	change := update.change(issue, id)
+	if !fs.cfg.Preview.Approve(change) {
+		return nil
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	stored, worklog := fs.worklog(id)
+	if stored == nil || stored.Key != issue && stored.Id != issue {
+		return issueNotFound("PUT", issue)
+	}
+	before := *worklog
+
+	if !update.Started.IsZero() {
+		worklog.Started = utils.CustomTime{Time: update.Started}
+	}
+	if update.TimeSpentSeconds > 0 {
+		worklog.TimeSpentSeconds = float64(update.TimeSpentSeconds)
+		worklog.TimeSpent = formatTimeSpent(update.TimeSpentSeconds)
+	}
+	if update.Comment != nil {
+		worklog.Comment = adf.FromMarkdown(*update.Comment)
+	}
+	switch {
+	case update.Visibility == nil:
+	case update.Visibility.IsZero():
+		worklog.Visibility = nil
+	default:
+		worklog.Visibility = update.Visibility
+	}
+	worklog.Updated = utils.CustomTime{Time: time.Now()}
+	stored.Updated = time.Now()
+
+	recordChange(fs.cfg.Journal, change, id, &before)
+
+	return nil
+}
+
+// DeleteWorklog removes the worklog id of issue, issue being its key or its
+// id, adjusting the remaining estimate of issue the way adjust asks.
+func (fs *FakeJiraService) DeleteWorklog(ctx context.Context, issue string, id string, adjust DeleteAdjustment) error {
+	change := Change{Action: ActionDeleteWorklog, Issue: issue, Worklog: id, AdjustEstimate: adjust.AdjustEstimate, AdjustBy: adjust.IncreaseBy}
+	if !fs.cfg.Preview.Approve(change) {
+		return nil
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	stored, worklog := fs.worklog(id)
+	if stored == nil || stored.Key != issue && stored.Id != issue {
+		return issueNotFound("DELETE", issue)
+	}
+	before := *worklog
+
+	for i := range stored.Worklogs {
+		if stored.Worklogs[i].Id == id {
+			stored.Worklogs = append(stored.Worklogs[:i], stored.Worklogs[i+1:]...)
+			break
+		}
+	}
+	stored.Updated = time.Now()
+
+	if tracking, ok := fs.tracking[stored.Key]; ok {
+		fs.tracking[stored.Key] = tracking.deleted(int(before.TimeSpentSeconds), adjust)
+	}
+
+	recordChange(fs.cfg.Journal, change, id, &before)
+
+	return nil
+}
+
+func (fs *FakeJiraService) GetMySelf(ctx context.Context) error {
+	return nil
+}
+
+// UpdateIssue moves issue to status, any status being reachable from any other.
+func (fs *FakeJiraService) UpdateIssue(ctx context.Context, issue string, status string) error {
+	// held throughout, so that no other move slips in between reading the
+	// status and writing the new one
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	stored := fs.issue(issue)
+	if stored == nil {
+		return issueNotFound("POST", issue)
+	}
+
+	from := fs.status(stored.Key)
+	if strings.EqualFold(from, status) {
+		return nil
+	}
+
+	change := Change{Action: ActionTransition, Issue: issue, Status: status}
+	if !fs.cfg.Preview.Approve(change) {
+		return nil
+	}
+
+	fs.statuses[stored.Key] = status
+
+	recordTransition(fs.cfg.Journal, change, from)
+
+	return nil
+}
+
+// issue looks up a stored issue by key or id, as Jira does. fs.mu must be held.
+func (fs *FakeJiraService) issue(key string) *Issue {
+	for i := range fs.issues {
+		if fs.issues[i].Key == key || fs.issues[i].Id == key {
+			return &fs.issues[i]
+		}
+	}
+
+	return nil
+}
+
+// worklog looks up a stored worklog and its issue by the worklog id. fs.mu must be held.
+func (fs *FakeJiraService) worklog(id string) (*Issue, *WorklogResponseObject) {
+	for i := range fs.issues {
+		for j := range fs.issues[i].Worklogs {
+			if fs.issues[i].Worklogs[j].Id == id {
+				return &fs.issues[i], &fs.issues[i].Worklogs[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// issueNotFound is the error Jira answers with for an unknown issue key.
+func issueNotFound(method string, key string) error {
+	return &JiraError{
+		StatusCode:    http.StatusNotFound,
+		Method:        method,
+		Path:          fmt.Sprintf("/rest/api/3/issue/%s", key),
+		ErrorMessages: []string{"Issue does not exist or you do not have permission to see it."},
+	}
+}
+
+// logged is tt once the worklog of params is posted, its remaining estimate
+// adjusted the way params asks and never below zero.
+func (tt TimeTracking) logged(params utils.LogWorkParams) TimeTracking {
+	seconds := params.Seconds()
+	tt.TimeSpentSeconds += seconds
+
+	switch string(params.AdjustEstimate) {
+	case utils.AdjustEstimateLeave:
+	case utils.AdjustEstimateNew:
+		tt.RemainingEstimateSeconds = int(*params.NewEstimate / time.Second)
+	case utils.AdjustEstimateManual:
+		tt.RemainingEstimateSeconds -= int(params.ReduceBy / time.Second)
+	default:
+		tt.RemainingEstimateSeconds -= seconds
+	}
+	tt.RemainingEstimateSeconds = max(tt.RemainingEstimateSeconds, 0)
+
+	tt.TimeSpent = formatTimeSpent(tt.TimeSpentSeconds)
+	tt.RemainingEstimate = formatTimeSpent(tt.RemainingEstimateSeconds)
+
+	return tt
+}
+
+// deleted is tt once a worklog of seconds is deleted, its time given back to
+// the remaining estimate the way adjust asks.
+func (tt TimeTracking) deleted(seconds int, adjust DeleteAdjustment) TimeTracking {
+	tt.TimeSpentSeconds = max(tt.TimeSpentSeconds-seconds, 0)
+
+	switch string(adjust.AdjustEstimate) {
+	case utils.AdjustEstimateLeave:
+	case utils.AdjustEstimateManual:
+		tt.RemainingEstimateSeconds += int(adjust.IncreaseBy / time.Second)
+	default:
+		tt.RemainingEstimateSeconds += seconds
+	}
+
+	tt.TimeSpent = formatTimeSpent(tt.TimeSpentSeconds)
+	tt.RemainingEstimate = formatTimeSpent(tt.RemainingEstimateSeconds)
+
+	return tt
+}
+
+// formatTimeSpent renders seconds the way Jira fills in timeSpent, i.e "2h 30m".
+func formatTimeSpent(seconds int) string {
+	var parts []string
+
+	if hours := seconds / 3600; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+
+	if minutes := seconds % 3600 / 60; minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
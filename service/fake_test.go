@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alinsimion/jira-cli/journal"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+func TestFakeGetUsersIssuesFromPeriod(t *testing.T) {
+	day := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+	worklog := func(author JiraUser) WorklogResponseObject {
+		return WorklogResponseObject{Author: author, Started: utils.CustomTime{Time: day}, TimeSpentSeconds: 3600}
+	}
+
+	tests := []struct {
+		name      string
+		user      JiraUser
+		colleague JiraUser
+	}{
+		{
+			name:      "cloud",
+			user:      JiraUser{AccountId: "5b10ac8d82e05b22cc7d4ef5", DisplayName: "John Doe"},
+			colleague: JiraUser{AccountId: "5b10a2844c20165700ede21g", DisplayName: "Jane Roe"},
+		},
+		{
+			// Jira Server users have no account id
+			name:      "server",
+			user:      JiraUser{Name: "jdoe", DisplayName: "John Doe"},
+			colleague: JiraUser{Name: "jroe", DisplayName: "Jane Roe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewFakeJiraService(tt.user,
+				Issue{Key: "GAIA-1", Worklogs: []WorklogResponseObject{worklog(tt.user)}},
+				Issue{Key: "GAIA-2", Worklogs: []WorklogResponseObject{worklog(tt.colleague)}},
+				Issue{Key: "GAIA-3", Worklogs: []WorklogResponseObject{worklog(tt.colleague), worklog(tt.user)}},
+			)
+
+			issues, err := fs.GetUsersIssuesFromPeriod(context.Background(), day, day)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var keys []string
+			for _, issue := range issues {
+				keys = append(keys, issue.Key)
+			}
+			if want := []string{"GAIA-1", "GAIA-3"}; !slices.Equal(keys, want) {
+				t.Errorf("got issues %v, want %v", keys, want)
+			}
+		})
+	}
+}
+
+func TestFakeUpdateIssueConcurrently(t *testing.T) {
+	fs := NewFakeJiraService(JiraUser{Name: "jdoe"}, Issue{Key: "GAIA-1"})
+
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	cfg := DefaultConfig()
+	cfg.Journal = journal.New(path, "test")
+	fs.Configure(cfg)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fs.UpdateIssue(context.Background(), "GAIA-1", "Done"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	runs, err := journal.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the issue moved once, the other moves found it done already
+	if len(runs) != 1 || len(runs[0].Entries) != 1 {
+		t.Errorf("journaled %v, want a single transition", runs)
+	}
+}
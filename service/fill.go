@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+func (js *JiraService) PlanFill(ctx context.Context, params utils.FillParams) ([]utils.LogWorkParams, error) {
+	return planFill(ctx, js, params, js.cfg, js.User)
+}
+
+// planFill works out the worklogs that bring every working day of the
+// params range up to params.Target hours, sharing the missing hours of a
+// day between issues as params says, or up to the hours of the cfg schedule
+// when params.ScheduledTarget. Days off and holidays are left alone,
+// absences lower the target by the part of the day they take. It is shared
+// by every JiraClient. Days whose hours cannot be shared, because nothing
+// was logged the same week to share them like, are left out of the plan
+// and reported in the error.
+func planFill(ctx context.Context, client JiraClient, params utils.FillParams, cfg Config, user JiraUser) ([]utils.LogWorkParams, error) {
+	days, err := utils.RangeFromFlags(params.Period, params.From, params.To, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := timesheet(ctx, client, user, days, cfg.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := []utils.LogWorkParams{}
+	var unshared []string
+	for _, date := range days.Dates() {
+		if !cfg.Schedule.IsWorkday(date) || cfg.Holidays.IsHoliday(date) {
+			continue
+		}
+
+		day := date.Format(time.DateOnly)
+
+		target := params.Target
+		if params.ScheduledTarget {
+			target = cfg.Schedule.TimeOn(date, target)
+		}
+		var logged time.Duration
+		for _, spent := range sheet[day] {
+			logged += spent
+		}
+
+		// the absence takes its part of the day, booked on the absence issue or not
+		if away, ok := cfg.Absences.On(date); ok {
+			target -= time.Duration(float64(target) * float64(away.Kind.Fraction()))
+			logged -= sheet[day][cfg.AbsenceIssue]
+		}
+
+		missing := target - logged
+		if missing < minimumTime {
+			continue
+		}
+
+		weights := fillWeights(params, sheet, days, date, cfg.AbsenceIssue)
+		if len(weights) == 0 {
+			unshared = append(unshared, day)
+			continue
+		}
+
+		for _, share := range shareTime(missing, weights) {
+			plan = append(plan, utils.LogWorkParams{
+				Date:      utils.GetSimpleDateFormat(date),
+				IssueKey:  share.issue,
+				TimeSpent: share.spent,
+				Message:   params.Message,
+			})
+		}
+	}
+
+	if len(unshared) > 0 {
+		return plan, fmt.Errorf("nothing was logged the same week as %s to share the missing hours like, give --issues or --weights", strings.Join(unshared, ", "))
+	}
+
+	return plan, nil
+}
+
+// fillWeights is how the missing time of date is shared between issues:
+// equally between params.Issues, by params.Weights, or else like the time
+// logged during the ISO week of date, the absence issue left out.
+func fillWeights(params utils.FillParams, sheet map[string]map[string]time.Duration, days period.Range, date time.Time, absenceIssue string) map[string]float32 {
+	weights := map[string]float32{}
+
+	if len(params.Issues) > 0 {
+		for _, issue := range params.Issues {
+			weights[issue] = 1
+		}
+		return weights
+	}
+
+	if len(params.Weights) > 0 {
+		for issue, weight := range params.Weights {
+			if weight > 0 {
+				weights[issue] = weight
+			}
+		}
+		return weights
+	}
+
+	year, number := date.ISOWeek()
+	week, _ := period.ISOWeek(year, number, date.Location())
+	for _, weekDay := range week.Dates() {
+		if !days.Contains(weekDay) {
+			continue
+		}
+
+		for issue, spent := range sheet[weekDay.Format(time.DateOnly)] {
+			if issue != absenceIssue {
+				weights[issue] += float32(spent.Hours())
+			}
+		}
+	}
+
+	return weights
+}
+
+type timeShare struct {
+	issue string
+	spent time.Duration
+}
+
+// shareTime splits spent between issues by weight, in whole minutes, the
+// last issue taking what rounding left over. Issues are sorted by key.
+func shareTime(spent time.Duration, weights map[string]float32) []timeShare {
+	issues := make([]string, 0, len(weights))
+	var total float32
+	for issue, weight := range weights {
+		issues = append(issues, issue)
+		total += weight
+	}
+	sort.Strings(issues)
+
+	minutes := int(spent.Round(time.Minute) / time.Minute)
+
+	var shares []timeShare
+	left := minutes
+	for i, issue := range issues {
+		share := int(math.Round(float64(minutes) * float64(weights[issue]/total)))
+		if i == len(issues)-1 || share > left {
+			share = left
+		}
+		left -= share
+
+		if share > 0 {
+			shares = append(shares, timeShare{issue: issue, spent: time.Duration(share) * time.Minute})
+		}
+	}
+
+	return shares
+}
+
+// PlanTable arranges the worklogs of plan into an issue key -> day -> time
+// spent table, the way worklogs are listed for days.
+func PlanTable(plan []utils.LogWorkParams, days period.Range) map[string]map[string][]string {
+	table := map[string]map[string][]string{}
+
+	for _, params := range plan {
+		date, err := utils.ParseDate(params.Date)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := table[params.IssueKey]; !ok {
+			table[params.IssueKey] = map[string][]string{}
+		}
+
+		day := tableDay(date, days)
+		table[params.IssueKey][day] = append(table[params.IssueKey][day], utils.FormatDuration(params.TimeSpent))
+	}
+
+	return table
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alinsimion/jira-cli/absence"
+	"github.com/alinsimion/jira-cli/holidays"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+func TestShareTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		spent   time.Duration
+		weights map[string]float32
+		want    []timeShare
+	}{
+		{
+			name:    "equally",
+			spent:   4 * time.Hour,
+			weights: map[string]float32{"GAIA-2": 1, "GAIA-1": 1},
+			want:    []timeShare{{"GAIA-1", 2 * time.Hour}, {"GAIA-2", 2 * time.Hour}},
+		},
+		{
+			name:    "by weight",
+			spent:   7 * time.Hour,
+			weights: map[string]float32{"GAIA-1": 3, "GAIA-2": 1},
+			want:    []timeShare{{"GAIA-1", 315 * time.Minute}, {"GAIA-2", 105 * time.Minute}},
+		},
+		{
+			// the last issue takes the minute rounding left over
+			name:    "not dividing evenly",
+			spent:   100 * time.Minute,
+			weights: map[string]float32{"GAIA-1": 1, "GAIA-2": 1, "GAIA-3": 1},
+			want:    []timeShare{{"GAIA-1", 33 * time.Minute}, {"GAIA-2", 33 * time.Minute}, {"GAIA-3", 34 * time.Minute}},
+		},
+		{
+			name:    "too little to share",
+			spent:   time.Minute,
+			weights: map[string]float32{"GAIA-1": 1, "GAIA-2": 1, "GAIA-3": 1},
+			want:    []timeShare{{"GAIA-3", time.Minute}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shareTime(tt.spent, tt.weights); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanFill(t *testing.T) {
+	user := JiraUser{Name: "jdoe", DisplayName: "John Doe"}
+	monday := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.Local)
+	worklog := func(started time.Time, spent time.Duration) WorklogResponseObject {
+		return WorklogResponseObject{Author: user, Started: utils.CustomTime{Time: started}, TimeSpentSeconds: spent.Seconds()}
+	}
+
+	tests := []struct {
+		name   string
+		params utils.FillParams
+		want   []string
+	}{
+		{
+			name:   "equally",
+			params: utils.FillParams{Issues: []string{"GAIA-1", "GAIA-2"}},
+			want: []string{
+				"2024-03-05 GAIA-1 2h 30m", "2024-03-05 GAIA-2 2h 30m",
+				"2024-03-07 GAIA-1 2h", "2024-03-07 GAIA-2 2h",
+				"2024-03-08 GAIA-1 4h", "2024-03-08 GAIA-2 4h",
+			},
+		},
+		{
+			// 9h on GAIA-1 for 2h on GAIA-2 that week
+			name: "like the week",
+			want: []string{
+				"2024-03-05 GAIA-1 4h 5m", "2024-03-05 GAIA-2 55m",
+				"2024-03-07 GAIA-1 3h 16m", "2024-03-07 GAIA-2 44m",
+				"2024-03-08 GAIA-1 6h 33m", "2024-03-08 GAIA-2 1h 27m",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Monday is full, Tuesday partly logged, Wednesday a holiday
+			// and Thursday half a day off
+			fs := NewFakeJiraService(user,
+				Issue{Key: "GAIA-1", Worklogs: []WorklogResponseObject{worklog(monday, 6*time.Hour), worklog(monday.AddDate(0, 0, 1), 3*time.Hour)}},
+				Issue{Key: "GAIA-2", Worklogs: []WorklogResponseObject{worklog(monday, 2*time.Hour)}},
+			)
+
+			cfg := DefaultConfig()
+			cfg.Holidays = &holidays.Calendar{}
+			cfg.Holidays.Add(holidays.Holiday{Date: monday.AddDate(0, 0, 2), Name: "Some holiday"})
+
+			store, err := absence.Open(filepath.Join(t.TempDir(), "absences.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			thursday, _ := period.Between(monday.AddDate(0, 0, 3), monday.AddDate(0, 0, 3))
+			if err := store.Add(absence.New(thursday, absence.Kind(absence.KindHalfDay), "")); err != nil {
+				t.Fatal(err)
+			}
+			cfg.Absences = store
+
+			params := tt.params
+			params.From, params.To, params.Target = "2024-03-04", "2024-03-08", 8*time.Hour
+
+			plan, err := planFill(context.Background(), fs, params, cfg, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, params := range plan {
+				got = append(got, params.Date+" "+params.IssueKey+" "+utils.FormatDuration(params.TimeSpent))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got plan %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
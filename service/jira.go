@@ -1,24 +1,25 @@
 package service
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/alinsimion/jira-cli/absence"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/schedule"
 	"github.com/alinsimion/jira-cli/utils"
 )
 
-var (
-	requests = 0
-)
-
 type JiraUser struct {
 	AccountId   string            `json:"accountId"`
 	AccountType string            `json:"accountType"`
@@ -26,19 +27,23 @@ type JiraUser struct {
 	Active      bool              `json:"active"`
 	DisplayName string            `json:"displayName"`
 	Email       string            `json:"emailAddress"`
+	// Name and Key identify users on Jira Server, which has no account ids
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 type WorklogResponseObject struct {
-	Author           JiraUser         `json:"author"`
-	Comment          map[string]any   `json:"comment"`
-	Id               string           `json:"id"`
-	IssueId          string           `json:"issueId"`
-	TimeSpent        string           `json:"timeSpent"`
-	TimeSpentSeconds float64          `json:"timeSpentSeconds"`
-	UpdateAuthor     JiraUser         `json:"updateAuthor"`
-	Started          utils.CustomTime `json:"started"`
-	Updated          utils.CustomTime `json:"updated"`
-	Created          utils.CustomTime `json:"created"`
+	Author           JiraUser          `json:"author"`
+	Comment          any               `json:"comment"` // an ADF document on Cloud, plain text on Server
+	Id               string            `json:"id"`
+	IssueId          string            `json:"issueId"`
+	TimeSpent        string            `json:"timeSpent"`
+	TimeSpentSeconds float64           `json:"timeSpentSeconds"`
+	UpdateAuthor     JiraUser          `json:"updateAuthor"`
+	Started          utils.CustomTime  `json:"started"`
+	Updated          utils.CustomTime  `json:"updated"`
+	Created          utils.CustomTime  `json:"created"`
+	Visibility       *utils.Visibility `json:"visibility,omitempty"`
 }
 
 type WorklogsResponseObject struct {
@@ -48,9 +53,72 @@ type WorklogsResponseObject struct {
 	WorkLogs   []WorklogResponseObject `json:"worklogs"`
 }
 
+const (
+	// TotalRow is the worklog table row summing the time spent each day, drawn after the issues.
+	TotalRow = "Total"
+	// HolidaysRow is the worklog table row marking holidays, drawn after the issues.
+	HolidaysRow = "Holidays"
+	HolidayMark = "H"
+	// AbsencesRow is the worklog table row marking absences, drawn after the issues.
+	AbsencesRow = "Absences"
+)
+
+// minimumTime is the shortest worklog Jira accepts.
+const minimumTime = time.Minute
+
+// worklogPageSize is how many worklogs are asked for at once, Jira caps it at 5000.
+const worklogPageSize = 1000
+
+// WorklogQuery restricts the worklogs fetched for an issue to the ones
+// started in [StartedAfter, StartedBefore). Zero bounds are left open.
+type WorklogQuery struct {
+	StartedAfter  time.Time
+	StartedBefore time.Time
+}
+
+// rangeQuery selects the worklogs started during days.
+func rangeQuery(days period.Range) WorklogQuery {
+	return WorklogQuery{
+		StartedAfter:  days.From,
+		StartedBefore: days.End(),
+	}
+}
+
+func (q WorklogQuery) values() url.Values {
+	values := url.Values{}
+
+	if !q.StartedAfter.IsZero() {
+		// startedAfter is exclusive, step back a millisecond to keep the bound itself
+		values.Set("startedAfter", strconv.FormatInt(q.StartedAfter.UnixMilli()-1, 10))
+	}
+
+	if !q.StartedBefore.IsZero() {
+		values.Set("startedBefore", strconv.FormatInt(q.StartedBefore.UnixMilli(), 10))
+	}
+
+	return values
+}
+
+// contains reports whether a worklog started at t falls inside the query.
+func (q WorklogQuery) contains(t time.Time) bool {
+	if !q.StartedAfter.IsZero() && t.Before(q.StartedAfter) {
+		return false
+	}
+
+	if !q.StartedBefore.IsZero() && !t.Before(q.StartedBefore) {
+		return false
+	}
+
+	return true
+}
+
 type IssuesResponse struct {
 	Issues        []Issue `json:"issues"`
 	NextPageToken string  `json:"nextPageToken"`
+	// StartAt, MaxResults and Total page the results of Jira Server's search
+	StartAt    int `json:"startAt"`
+	MaxResults int `json:"maxResults"`
+	Total      int `json:"total"`
 }
 
 type Issue struct {
@@ -95,393 +163,576 @@ type JiraService struct {
 	Email      string
 	User       JiraUser
 	UserIssues []Issue `json:"issues"`
+
+	cfg    Config
+	client *http.Client
+
+	detect   sync.Once
+	detected string
 }
 
-func NewJiraService(apiToken string, endpoint string, email string) JiraService {
-	js := JiraService{
+func NewJiraService(apiToken string, endpoint string, email string) *JiraService {
+	js := &JiraService{
 		APIToken: apiToken,
 		Endpoint: endpoint,
 		Email:    email,
 	}
 
-	js.GetMySelf()
+	js.Configure(DefaultConfig())
 
 	return js
 }
 
-func (js *JiraService) MakeJiraRequest(urlPath string, method string, payload map[string]any) (*http.Response, error) {
-	baseUrl := fmt.Sprintf("https://%s/%s", js.Endpoint, urlPath)
-	var request *http.Request
-	var err error
-
-	if method == "GET" {
-		request, err = http.NewRequest(method, baseUrl, nil)
+// Configure replaces the http client shared by every request with one built from cfg.
+func (js *JiraService) Configure(cfg Config) {
+	js.cfg = cfg
+	js.client = cfg.httpClient()
+	js.detect = sync.Once{}
+}
 
-		if err != nil {
-			slog.Error("Error while getting worklog ", "error", err.Error())
-			return nil, err
-		}
+// MakeJiraRequest sends a request to Jira. Requests Jira turned away because
+// of rate limiting or a transient gateway failure are retried with backoff,
+// up to the configured number of attempts. The caller closes the response body.
+func (js *JiraService) MakeJiraRequest(ctx context.Context, req Request) (*http.Response, error) {
+	body, contentType, err := req.body()
+	if err != nil {
+		return nil, err
 	}
 
-	if method == "POST" {
+	idempotent := isIdempotent(req.Method, req.Path)
 
-		jsonData, err := json.Marshal(payload)
+	for attempt := 1; ; attempt++ {
+		request, err := req.newHTTPRequest(ctx, js.baseURL(), body, contentType)
 		if err != nil {
-			slog.Error("Error while marshaling json", "error", err.Error())
+			slog.Error("Error while building request", "error", err.Error())
 			return nil, err
 		}
 
-		request, err = http.NewRequest(method, baseUrl, bytes.NewBuffer(jsonData))
+		js.authorize(request)
 
+		start := time.Now()
+		response, err := js.client.Do(request)
+		countRequest(time.Since(start))
 		if err != nil {
-			slog.Error("Error while getting worklog ", "error", err.Error())
+			slog.Error("Error while sending request to jira", "error", err.Error())
 			return nil, err
 		}
-	}
 
-	request.SetBasicAuth(js.Email, js.APIToken)
-	request.Header.Set("Accept", "application/json")
-	request.Header.Set("Content-Type", "application/json")
+		if attempt >= js.cfg.MaxAttempts || !shouldRetry(response.StatusCode, idempotent) {
+			return response, nil
+		}
 
-	client := &http.Client{}
+		wait := retryDelay(response, attempt)
+		slog.Warn("Jira request failed, retrying", "method", req.Method, "path", req.Path, "status", response.StatusCode, "attempt", attempt, "wait", wait)
 
-	response, err := client.Do(request)
-	if err != nil {
-		slog.Error("Error while logging work", "error", err.Error())
-		return nil, err
+		io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
 	}
-	return response, nil
 }
 
-func (js *JiraService) LogWorkMulti(params utils.LogWorkParams) error {
-	if params.Date != utils.TODAY_FLAG {
-		return js.LogWork(params)
-	} else if params.Period != "" {
-		var errorMessages []string
-		if params.Period == utils.Period(utils.PeriodMonth) {
-
-			currentMonth := time.Now().Month()
-
-			for i := 0; i <= time.Now().Day(); i++ {
-				date := time.Date(time.Now().Year(), currentMonth, i+1, 0, 0, 0, 0, time.Local)
-				simpleDate := utils.GetSimpleDateFormat(date)
-				tempParams := params
-				tempParams.Date = simpleDate
-				err := js.LogWork(tempParams)
-				if err != nil {
-					errorMessages = append(errorMessages, err.Error())
-				}
-			}
+func (js *JiraService) LogWorkMulti(ctx context.Context, params utils.LogWorkParams) error {
+	return logWorkMulti(ctx, js, params, js.cfg, js.User)
+}
 
+// logWorkMulti books params on the date it names, or on every day of its
+// period or range, through the given client. It is shared by every JiraClient.
+// The days off of the cfg schedule, unless params.AllowWeekend, and the
+// holidays of cfg are skipped. When params.ScheduledTime, each day gets the
+// hours the schedule gives it. The absences of cfg are booked on
+// cfg.AbsenceIssue when it is set and skipped otherwise, a half day leaving
+// half of the time on params.IssueKey. Unless params.Mode is
+// LogModeAdd, the work user already logged on each day is fetched first and
+// only what params.Mode says is missing gets logged. When ctx is cancelled
+// midway it stops and reports what was already posted.
+func logWorkMulti(ctx context.Context, client JiraClient, params utils.LogWorkParams, cfg Config, user JiraUser) error {
+	if !params.HasRange() {
+		date, err := workLogDate(params, cfg.Schedule)
+		if err != nil {
+			return err
 		}
 
-		if params.Period == utils.Period(utils.PeriodLastMonth) {
-			currentYear := time.Now().Year()
-			currentMonth := time.Now().Month()
-			previousMonth := currentMonth - 1
-			if currentMonth == time.January {
-				previousMonth = time.December
-				currentYear = currentYear - 1
-			}
+		if params.ScheduledTime {
+			params.TimeSpent = cfg.Schedule.TimeOn(date, params.TimeSpent)
+		}
 
-			for i := range utils.DaysInMonth(previousMonth) {
-				date := time.Date(currentYear, previousMonth, i+1, 0, 0, 0, 0, time.Local)
-				simpleDate := utils.GetSimpleDateFormat(date)
-				tempParams := params
-				tempParams.Date = simpleDate
-				err := js.LogWork(tempParams)
-				if err != nil {
-					errorMessages = append(errorMessages, err.Error())
-				}
-			}
+		if !params.ChecksLogged() {
+			return client.LogWork(ctx, params)
 		}
 
-		if params.Period == utils.Period(utils.PeriodWeek) {
-			currentDayOfWeek := time.Now().Weekday()
-			startOfWeek := time.Now().Day() - int(currentDayOfWeek) + 1
-			for i := startOfWeek; i <= time.Now().Day(); i += 1 {
+		day, err := period.Between(date, date)
+		if err != nil {
+			return err
+		}
 
-				date := time.Date(time.Now().Year(), time.Now().Month(), int(i), 0, 0, 0, 0, time.Local)
-				if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
-					continue
-				}
+		logged, err := loggedTime(ctx, client, user, day, cfg.Concurrency)
+		if err != nil {
+			return err
+		}
 
-				simpleDate := utils.GetSimpleDateFormat(date)
-				tempParams := params
-				tempParams.Date = simpleDate
-				err := js.LogWork(tempParams)
-				if err != nil {
-					errorMessages = append(errorMessages, err.Error())
-				}
+		params.TimeSpent = params.Mode.Missing(params.TimeSpent, logged[date.Format(time.DateOnly)])
+		if params.TimeSpent < minimumTime {
+			slog.Info("work is logged already, no need to log work.", "date", utils.GetSimpleDateFormat(date), "mode", string(params.Mode))
+			return nil
+		}
 
-			}
+		return client.LogWork(ctx, params)
+	}
+
+	days, err := utils.RangeFromFlags(params.Period, params.From, params.To, time.Now())
+	if err != nil {
+		return err
+	}
 
+	logged := map[string]time.Duration{}
+	if params.ChecksLogged() {
+		logged, err = loggedTime(ctx, client, user, days, cfg.Concurrency)
+		if err != nil {
+			return err
 		}
+	}
 
-		if params.Period == utils.Period(utils.PeriodLastWeek) {
-			return errors.New("Not Implemented")
+	var errs []error
+	var posted []string
+
+	// book posts p and tells whether ctx was cancelled meanwhile
+	book := func(p utils.LogWorkParams) bool {
+		err := client.LogWork(ctx, p)
+		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Printf("Request for %s was interrupted and may not have been applied\n", p.Date)
+				return true
+			}
+			errs = append(errs, err)
+			return false
 		}
 
-		if len(errorMessages) > 0 {
-			return errors.New(strings.Join(errorMessages, "\n"))
+		if p.IssueKey == params.IssueKey {
+			posted = append(posted, p.Date)
+		} else {
+			posted = append(posted, fmt.Sprintf("%s (%s)", p.Date, p.IssueKey))
 		}
+		return false
 	}
 
-	return nil
-}
+	for _, date := range days.Dates() {
+		if !params.AllowWeekend && !cfg.Schedule.IsWorkday(date) {
+			continue
+		}
 
-func (js *JiraService) LogWork(params utils.LogWorkParams) error {
-	urlPath := fmt.Sprintf("rest/api/3/issue/%s/worklog", params.IssueKey)
-	var day int
-	var month int
-	var year int
+		if name, ok := cfg.Holidays.Holiday(date); ok {
+			slog.Info("is a holiday, no need to log work.", "date", utils.GetSimpleDateFormat(date), "holiday", name)
+			continue
+		}
 
-	var err error
+		if ctx.Err() != nil {
+			break
+		}
 
-	if params.Date == utils.TODAY_FLAG {
-		year = time.Now().Year()
-		month = int(time.Now().Month())
-		day = time.Now().Day()
-	} else {
-		parts := strings.Split(params.Date, "/")
+		tempParams := params
+		tempParams.Date = utils.GetSimpleDateFormat(date)
+		tempParams.Period, tempParams.From, tempParams.To = "", "", ""
 
-		day, err = strconv.Atoi(parts[0])
+		want := params.TimeSpent
+		if params.ScheduledTime {
+			want = cfg.Schedule.TimeOn(date, want)
+		}
 
-		if err != nil {
-			slog.Error("error while parsing day from date", "error", err.Error())
-			return err
+		tempParams.TimeSpent = params.Mode.Missing(want, logged[date.Format(time.DateOnly)])
+		if tempParams.TimeSpent < minimumTime {
+			slog.Info("work is logged already, no need to log work.", "date", tempParams.Date, "mode", string(params.Mode))
+			continue
 		}
 
-		month, err = strconv.Atoi(parts[1])
-		if err != nil {
-			slog.Error("error while parsing month from date", "error", err.Error())
-			return err
+		if away, ok := cfg.Absences.On(date); ok {
+			absent := min(time.Duration(float64(want)*float64(away.Kind.Fraction())), tempParams.TimeSpent)
+			tempParams.TimeSpent -= absent
+
+			if cfg.AbsenceIssue == "" {
+				slog.Info("is an absence, no need to log work.", "date", tempParams.Date, "absence", away.String())
+			} else {
+				absenceParams := tempParams
+				absenceParams.IssueKey = cfg.AbsenceIssue
+				absenceParams.TimeSpent = absent
+				absenceParams.Message = absenceMessage(away)
+
+				if book(absenceParams) {
+					break
+				}
+			}
+
+			if tempParams.TimeSpent < minimumTime {
+				continue
+			}
 		}
 
-		year, err = strconv.Atoi(parts[2])
-		if err != nil {
-			slog.Error("error while parsing year from date", "error", err.Error())
-			return err
+		if book(tempParams) {
+			break
 		}
 	}
 
-	tempDate := time.Date(int(year), time.Month(int(month)), int(day), 10, 0, 0, 0, time.Local)
-	started := tempDate.Format("2006-01-02T15:04:05.000-0700")
-
-	if tempDate.Weekday() == 0 || tempDate.Weekday() == 6 {
-		slog.Info("is on the weekend no need to log work.", "date", tempDate.Format(time.RFC1123))
-		return nil
+	if ctx.Err() != nil {
+		fmt.Printf("Interrupted: work was logged on %s for %d day(s): %s\n", params.IssueKey, len(posted), strings.Join(posted, ", "))
+		return ctx.Err()
 	}
 
-	payload := map[string]any{
-		"comment": map[string]any{
-			"content": []map[string]any{
-				{
-					"content": []map[string]any{
-						{
-							"text": params.Message,
-							"type": "text",
-						},
-					},
-					"type": "paragraph",
-				},
-			},
-			"type":    "doc",
-			"version": 1,
-		},
-		"started":          started,
-		"timeSpentSeconds": params.TimeSpent * 60 * 60,
-	}
+	return errors.Join(errs...)
+}
 
-	response, err := js.MakeJiraRequest(urlPath, "POST", payload)
-	if err != nil {
-		slog.Error("error while logging work", "error", err.Error())
-		return err
+// absenceMessage is the comment of the worklog booking away.
+func absenceMessage(away absence.Absence) string {
+	if away.Note != "" {
+		return fmt.Sprintf("%s: %s", away.Kind, away.Note)
 	}
 
-	if response.StatusCode == http.StatusCreated {
-		var worklogResponse WorklogResponseObject
-
-		readData, err := io.ReadAll(response.Body)
+	return string(away.Kind)
+}
 
-		if err != nil {
-			slog.Error("Error while reading response from getting log work", "error", err.Error())
-			return err
-		}
+// workLogDate returns the moment a worklog for params is started at, the
+// start of the working day of sched unless params.Started is set.
+func workLogDate(params utils.LogWorkParams, sched *schedule.Schedule) (time.Time, error) {
+	if !params.Started.IsZero() {
+		return params.Started, nil
+	}
 
-		err = json.Unmarshal(readData, &worklogResponse)
+	date := time.Now()
 
+	if params.Date != utils.TODAY_FLAG {
+		var err error
+		date, err = utils.ParseDate(params.Date)
 		if err != nil {
-			slog.Error("Error while unmarshaling  log work reponse", "error", err.Error())
-			return err
+			slog.Error("error while parsing date", "error", err.Error())
+			return time.Time{}, err
 		}
+	}
 
-		// jsonFormat, err := json.MarshalIndent(worklogResponse, "", "	")
-
-		// if err != nil {
-		// 	slog.Error("Error while marshaling log work response", "error", err.Error())
-		// 	return err
-		// }
-		// fmt.Println(string(jsonFormat))
-
-		fmt.Printf("%s of work logged for %s on %s \n", worklogResponse.TimeSpent, worklogResponse.Author.DisplayName, worklogResponse.Started)
+	return sched.StartOn(date), nil
+}
 
+// checkWorkday fails when params would start a worklog on date, a day off of
+// sched, without asking for it: neither an exact start nor
+// params.AllowWeekend was given.
+func checkWorkday(params utils.LogWorkParams, date time.Time, sched *schedule.Schedule) error {
+	if !params.Started.IsZero() || params.AllowWeekend || sched.IsWorkday(date) {
 		return nil
+	}
 
-	} else {
-		type WorkLogError struct {
-			ErrorMessages []string       `json:"errorMessages"`
-			Errors        map[string]any `json:"errors"`
-		}
-		var workLogError WorkLogError
+	return fmt.Errorf("%s is not a working day, give --allow-weekend to log work on it anyway", date.Format("Monday 02/01/2006"))
+}
 
-		readData, err := io.ReadAll(response.Body)
+func (js *JiraService) LogWork(ctx context.Context, params utils.LogWorkParams) error {
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s/worklog", params.IssueKey))
 
-		if err != nil {
-			slog.Error("Error while reading response from getting log work", "error", err.Error())
-			return err
-		}
+	tempDate, err := workLogDate(params, js.cfg.Schedule)
+	if err != nil {
+		return err
+	}
+	started := tempDate.Format("2006-01-02T15:04:05.000-0700")
 
-		err = json.Unmarshal(readData, &workLogError)
+	if err := checkWorkday(params, tempDate, js.cfg.Schedule); err != nil {
+		return err
+	}
 
-		if err != nil {
-			slog.Error("Error while unmarshaling  log work reponse", "error", err.Error())
-			return err
-		}
+	// before the preview, so a dry run shows the worklogs going over too
+	if err := checkEstimate(ctx, js, params); err != nil {
+		return err
+	}
 
-		// jsonFormat, err := json.MarshalIndent(workLogError, "", "	")
+	change := Change{
+		Action:           ActionLogWork,
+		Issue:            params.IssueKey,
+		Started:          tempDate,
+		TimeSpentSeconds: params.Seconds(),
+		Comment:          params.Message,
+		AdjustEstimate:   params.AdjustEstimate,
+		AdjustBy:         params.ReduceBy,
+	}
+	if !params.Visibility.IsZero() {
+		visibility := params.Visibility
+		change.Visibility = &visibility
+	}
+	if !js.cfg.Preview.Approve(change) {
+		return nil
+	}
 
-		// if err != nil {
-		// 	slog.Error("Error while marshaling log work response", "error", err.Error())
-		// 	return err
-		// }
+	payload := map[string]any{
+		"comment":          js.comment(ctx, params.Message),
+		"started":          started,
+		"timeSpentSeconds": params.Seconds(),
+	}
+	if !params.Visibility.IsZero() {
+		payload["visibility"] = params.Visibility
+	}
 
-		return errors.New(strings.Join(workLogError.ErrorMessages, "\n"))
+	var worklogResponse WorklogResponseObject
+
+	err = js.Do(ctx, Request{Method: http.MethodPost, Path: urlPath, Query: estimateValues(params), Body: payload}, &worklogResponse)
+	if err != nil {
+		slog.Error("error while logging work", "error", err.Error())
+		return err
 	}
 
+	fmt.Printf("%s of work logged for %s on %s \n", utils.FormatDuration(worklogResponse.Duration()), worklogResponse.Author.DisplayName, worklogResponse.Started)
+
+	recordChange(js.cfg.Journal, change, worklogResponse.Id, nil)
+
+	return nil
 }
 
-func (js *JiraService) GetUserWorkLogs(since time.Time) (map[string]map[string][]string, error) {
+func (js *JiraService) GetUserWorkLogs(ctx context.Context, days period.Range) (map[string]map[string][]string, error) {
 
 	table := map[string]map[string][]string{}
 
-	usersIssues, err := js.GetUsersIssuesFromPeriod(since, time.Now())
+	usersIssues, err := js.GetUsersIssuesFromPeriod(ctx, days.From, days.To)
 	if err != nil {
 		slog.Error("error whule getting user's in progress issuess", "error", err.Error())
 		return table, err
 	}
 
-	for i, issue := range usersIssues {
-		workLog, _ := js.GetWorkLogsForIssue(issue.Key)
-		usersIssues[i].Worklogs = workLog.WorkLogs
+	err = fetchIssueWorklogs(ctx, js, usersIssues, rangeQuery(days), js.cfg.Concurrency)
+	if err != nil {
+		slog.Error("error while getting worklogs of some issues", "error", err.Error())
+	}
+
+	return worklogTable(usersIssues, days, js.cfg), err
+}
+
+// worklogTable arranges the worklogs of issues started during days into an
+// issue key -> day -> time spent table. Days are days of the month when the
+// range lies in one month, dates otherwise. The holidays of cfg are marked
+// with HolidayMark in the HolidaysRow row, the absences on working days with
+// the mark of their kind in the AbsencesRow row, and the time spent each day
+// is summed in the TotalRow row.
+func worklogTable(issues []Issue, days period.Range, cfg Config) map[string]map[string][]string {
+	table := map[string]map[string][]string{}
+	totals := map[string]time.Duration{}
+
+	mark := func(row string, date time.Time, value string) {
+		if _, ok := table[row]; !ok {
+			table[row] = map[string][]string{}
+		}
+		table[row][tableDay(date, days)] = []string{value}
 	}
 
-	for _, issue := range usersIssues {
+	for _, date := range days.Dates() {
+		if cfg.Holidays.IsHoliday(date) {
+			mark(HolidaysRow, date, HolidayMark)
+			continue
+		}
 
-		if issue.Updated.Before(since) {
+		if !cfg.Schedule.IsWorkday(date) {
+			continue
+		}
+
+		if away, ok := cfg.Absences.On(date); ok {
+			mark(AbsencesRow, date, away.Kind.Mark())
+		}
+	}
+
+	for _, issue := range issues {
+
+		if issue.Updated.Before(days.From) {
 			continue
 		}
 
 		for _, worklog := range issue.Worklogs {
-			if worklog.Started.Month() != since.Month() || worklog.Started.Year() != since.Year() {
+			if !days.Contains(worklog.Started.Time) {
 				continue
 			}
 
+			day := tableDay(worklog.Started.Time, days)
+			spent := utils.FormatDuration(worklog.Duration())
+			totals[day] += worklog.Duration()
+
 			if _, ok := table[issue.Key]; ok {
-				table[issue.Key][fmt.Sprintf("%d", worklog.Started.Day())] = append(table[issue.Key][fmt.Sprintf("%d", worklog.Started.Day())], worklog.TimeSpent)
+				table[issue.Key][day] = append(table[issue.Key][day], spent)
 			} else {
 				table[issue.Key] = map[string][]string{
-					fmt.Sprintf("%d", worklog.Started.Day()): {worklog.TimeSpent},
+					day: {spent},
 				}
 			}
 		}
 	}
 
-	return table, nil
+	for day, total := range totals {
+		if _, ok := table[TotalRow]; !ok {
+			table[TotalRow] = map[string][]string{}
+		}
+		table[TotalRow][day] = []string{utils.FormatDuration(total)}
+	}
+
+	return table
 }
 
-func (js *JiraService) GetWorkLogsForIssue(issue string) (WorklogsResponseObject, error) {
+// tableDay is the column of date in the worklog table of days.
+func tableDay(date time.Time, days period.Range) string {
+	if days.SingleMonth() {
+		return fmt.Sprintf("%d", date.Day())
+	}
 
-	urlPath := fmt.Sprintf("rest/api/3/issue/%s/worklog", issue)
+	return date.Format(time.DateOnly)
+}
 
-	response, err := js.MakeJiraRequest(urlPath, "GET", nil)
+// GetWorkLogsForIssue pages through every worklog of issue, restricted to the
+// ones started inside query when its bounds are set.
+func (js *JiraService) GetWorkLogsForIssue(ctx context.Context, issue string, query WorklogQuery) (WorklogsResponseObject, error) {
 
-	if err != nil {
-		slog.Error("Error while requesting worklogs for issue", "error", err.Error())
-		return WorklogsResponseObject{}, err
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s/worklog", issue))
+
+	worklogs := []WorklogResponseObject{}
+
+	for {
+		values := query.values()
+		values.Set("startAt", strconv.Itoa(len(worklogs)))
+		values.Set("maxResults", strconv.Itoa(worklogPageSize))
+
+		var page WorklogsResponseObject
+
+		err := js.Do(ctx, Request{Method: http.MethodGet, Path: urlPath, Query: values}, &page)
+		if err != nil {
+			slog.Error("Error while getting worklogs for issue", "issue", issue, "error", err.Error())
+			return WorklogsResponseObject{}, err
+		}
+
+		worklogs = append(worklogs, page.WorkLogs...)
+
+		if len(page.WorkLogs) == 0 || len(worklogs) >= page.Total {
+			break
+		}
 	}
 
-	var worklogResponse WorklogsResponseObject
+	return WorklogsResponseObject{
+		MaxResults: len(worklogs),
+		Total:      len(worklogs),
+		WorkLogs:   worklogs,
+	}, nil
+}
+
+// GetTimeTracking returns the estimates and the time spent of issue.
+func (js *JiraService) GetTimeTracking(ctx context.Context, issue string) (TimeTracking, error) {
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s", issue))
 
-	readData, err := io.ReadAll(response.Body)
+	var response struct {
+		Fields struct {
+			TimeTracking TimeTracking `json:"timetracking"`
+		} `json:"fields"`
+	}
 
+	err := js.Do(ctx, Request{Method: http.MethodGet, Path: urlPath, Query: url.Values{"fields": {"timetracking"}}}, &response)
 	if err != nil {
-		slog.Error("Error while reading response from getting log work", "error", err.Error())
-		return WorklogsResponseObject{}, err
+		slog.Error("error while getting time tracking", "issue", issue, "error", err.Error())
+		return TimeTracking{}, err
 	}
 
-	err = json.Unmarshal(readData, &worklogResponse)
+	return response.Fields.TimeTracking, nil
+}
 
+// UpdateIssue moves issue to status through the transition of its workflow
+// leading there. An issue in status already is left alone.
+func (js *JiraService) UpdateIssue(ctx context.Context, issue string, status string) error {
+	from, err := js.issueStatus(ctx, issue)
 	if err != nil {
-		slog.Error("Error while unmarshaling  log work reponse", "error", err.Error())
-		return WorklogsResponseObject{}, err
+		return err
 	}
 
-	// other, err := json.MarshalIndent(worklogResponse, "", "	")
+	if strings.EqualFold(from, status) {
+		slog.Info("the issue is in the status already", "issue", issue, "status", from)
+		return nil
+	}
 
-	// if err != nil {
-	// 	slog.Error("Error while marshaling log work response", "error", err.Error())
-	// 	return WorklogsResponseObject{}, err
-	// }
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s/transitions", issue))
 
-	// fmt.Println(string(other))
+	var transitions struct {
+		Transitions []struct {
+			Id string `json:"id"`
+			To struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
 
-	return worklogResponse, err
-}
+	err = js.Do(ctx, Request{Method: http.MethodGet, Path: urlPath}, &transitions)
+	if err != nil {
+		slog.Error("error while getting transitions", "issue", issue, "error", err.Error())
+		return err
+	}
 
-func (js *JiraService) UpdateIssue(issue string, status string) error {
-	return nil
-}
+	var transitionId string
+	var reachable []string
+	for _, transition := range transitions.Transitions {
+		if strings.EqualFold(transition.To.Name, status) {
+			transitionId, status = transition.Id, transition.To.Name
+		}
+		reachable = append(reachable, transition.To.Name)
+	}
+	if transitionId == "" {
+		return fmt.Errorf("%s cannot be moved from %s to %s, it can go to %s", issue, from, status, reachable)
+	}
 
-func (js *JiraService) GetMySelf() error {
-	urlPath := "rest/api/3/myself"
-	response, err := js.MakeJiraRequest(urlPath, "GET", map[string]any{})
+	change := Change{Action: ActionTransition, Issue: issue, Status: status}
+	if !js.cfg.Preview.Approve(change) {
+		return nil
+	}
 
+	payload := map[string]any{"transition": map[string]any{"id": transitionId}}
+
+	err = js.Do(ctx, Request{Method: http.MethodPost, Path: urlPath, Body: payload}, nil)
 	if err != nil {
-		slog.Error("error while getting myself", "error", err.Error())
+		slog.Error("error while moving issue", "issue", issue, "status", status, "error", err.Error())
 		return err
 	}
 
-	var jiraUserResponse JiraUser
+	recordTransition(js.cfg.Journal, change, from)
 
-	readData, err := io.ReadAll(response.Body)
+	return nil
+}
+
+// issueStatus returns the name of the status issue is in.
+func (js *JiraService) issueStatus(ctx context.Context, issue string) (string, error) {
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s", issue))
+
+	var response struct {
+		Fields struct {
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
 
+	err := js.Do(ctx, Request{Method: http.MethodGet, Path: urlPath, Query: url.Values{"fields": {"status"}}}, &response)
 	if err != nil {
-		slog.Error("Error while reading response from getting log work", "error", err.Error())
-		return err
+		slog.Error("error while getting status", "issue", issue, "error", err.Error())
+		return "", err
 	}
 
-	err = json.Unmarshal(readData, &jiraUserResponse)
+	return response.Fields.Status.Name, nil
+}
 
+func (js *JiraService) GetMySelf(ctx context.Context) error {
+	urlPath := js.api(ctx, "myself")
+	var jiraUserResponse JiraUser
+
+	err := js.Do(ctx, Request{Method: http.MethodGet, Path: urlPath}, &jiraUserResponse)
 	if err != nil {
-		slog.Error("Error while unmarshaling  log work reponse", "error", err.Error())
+		slog.Error("error while getting myself", "error", err.Error())
 		return err
 	}
 
 	js.User = jiraUserResponse
 
-	// jsonFormat, err := json.MarshalIndent(jiraUserResponse, "", "	")
-
-	// if err != nil {
-	// 	slog.Error("Error while marshaling log work response", "error", err.Error())
-	// 	return err
-	// }
-
-	// fmt.Println(string(jsonFormat))
 	return nil
 }
 
-func (js *JiraService) GetIssues(jql string) ([]Issue, error) {
+func (js *JiraService) GetIssues(ctx context.Context, jql string) ([]Issue, error) {
+	if !js.isCloud(ctx) {
+		return js.searchServerIssues(ctx, jql)
+	}
+
 	urlPath := "rest/api/3/search/jql"
 
 	// maxResults maybe subject to local restrictions
@@ -500,88 +751,84 @@ func (js *JiraService) GetIssues(jql string) ([]Issue, error) {
 			data["nextPageToken"] = nextPageToken
 		}
 
-		response, err := js.MakeJiraRequest(urlPath, "POST", data)
+		var result IssuesResponse
 
+		err := js.Do(ctx, Request{Method: http.MethodPost, Path: urlPath, Body: data}, &result)
 		if err != nil {
 			slog.Error("error while getting user issues", "error", err.Error())
 			return []Issue{}, err
 		}
 
-		var result IssuesResponse
+		issues = append(issues, result.Issues...)
 
-		readData, err := io.ReadAll(response.Body)
+		if result.NextPageToken == "" {
+			break
+		} else {
+			nextPageToken = result.NextPageToken
+		}
 
-		if err != nil {
-			slog.Error("Error while reading response from getting log work", "error", err.Error())
-			return []Issue{}, err
+	}
+
+	return issues, nil
+}
+
+// searchServerIssues runs jql against Jira Server's search, which pages
+// with startAt instead of Cloud's nextPageToken.
+func (js *JiraService) searchServerIssues(ctx context.Context, jql string) ([]Issue, error) {
+	urlPath := "rest/api/2/search"
+
+	issues := []Issue{}
+
+	for {
+		data := map[string]any{
+			"jql":        jql,
+			"startAt":    len(issues),
+			"maxResults": 1000,
+			"fields":     []string{"key", "id", "summary", "updated"},
 		}
 
-		err = json.Unmarshal(readData, &result)
+		var result IssuesResponse
 
+		err := js.Do(ctx, Request{Method: http.MethodPost, Path: urlPath, Body: data}, &result)
 		if err != nil {
-			slog.Error("Error while unmarshaling log work reponse", "error", err.Error())
+			slog.Error("error while getting user issues", "error", err.Error())
 			return []Issue{}, err
 		}
 
 		issues = append(issues, result.Issues...)
 
-		if result.NextPageToken == "" {
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
 			break
-		} else {
-			nextPageToken = result.NextPageToken
 		}
-
 	}
 
 	return issues, nil
 }
 
-func (js *JiraService) GetUsersInProgressIssues() ([]Issue, error) {
+func (js *JiraService) GetUsersInProgressIssues(ctx context.Context) ([]Issue, error) {
 	jql := fmt.Sprintf("assignee = \"%s\" AND status IN (\"In Progress\")", js.User.DisplayName)
-	return js.GetIssues(jql)
+	return js.GetIssues(ctx, jql)
 }
 
-func (js *JiraService) GetUsersIssuesFromPeriod(start time.Time, end time.Time) ([]Issue, error) {
+// GetUsersIssuesFromPeriod returns the issues assigned to the user with work
+// logged from the day of start to the day of end, both included.
+func (js *JiraService) GetUsersIssuesFromPeriod(ctx context.Context, start time.Time, end time.Time) ([]Issue, error) {
 
-	from := fmt.Sprintf("%d/%0*d/%0*d", start.Year(), 2, start.Month(), 2, start.Day())
-	to := fmt.Sprintf("%d/%0*d/%0*d", end.Year(), 2, end.Month()+1, 2, end.Day())
+	from := start.Format("2006/01/02")
+	to := end.AddDate(0, 0, 1).Format("2006/01/02")
 	jql := fmt.Sprintf("assignee = \"%s\" AND worklogDate >= \"%s\" AND worklogDate < \"%s\"", js.User.DisplayName, from, to)
-	// fmt.Println(jql)
-	return js.GetIssues(jql)
+	return js.GetIssues(ctx, jql)
 }
 
-func (js *JiraService) GetIssueFields() error {
-	urlPath := "rest/api/3/field"
-	response, err := js.MakeJiraRequest(urlPath, "GET", map[string]any{})
-
-	if err != nil {
-		slog.Error("error while getting myself", "error", err.Error())
-		return err
-	}
-
+func (js *JiraService) GetIssueFields(ctx context.Context) error {
+	urlPath := js.api(ctx, "field")
 	var tempResponse []map[string]any
 
-	readData, err := io.ReadAll(response.Body)
-
+	err := js.Do(ctx, Request{Method: http.MethodGet, Path: urlPath}, &tempResponse)
 	if err != nil {
-		slog.Error("Error while reading response from getting log work", "error", err.Error())
+		slog.Error("error while getting issue fields", "error", err.Error())
 		return err
 	}
 
-	err = json.Unmarshal(readData, &tempResponse)
-
-	if err != nil {
-		slog.Error("Error while unmarshaling  log work reponse", "error", err.Error())
-		return err
-	}
-
-	// for _, elem := range tempResponse {
-	// fmt.Println(elem["key"])
-	// }
-
-	// temp, _ := json.MarshalIndent(tempResponse, "", " ")
-
-	// fmt.Println(string(temp))
-
 	return nil
 }
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alinsimion/jira-cli/journal"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+const (
+	ActionLogWork       = "log work"
+	ActionUpdateWorklog = "update worklog"
+	ActionDeleteWorklog = "delete worklog"
+	ActionTransition    = "transition issue"
+)
+
+// Change is what a mutating call is about to send to Jira.
+type Change struct {
+	Action           string
+	Issue            string
+	Worklog          string
+	Started          time.Time
+	TimeSpentSeconds int
+	Comment          string
+	// Visibility is the restriction the change sets, nil when it sets none
+	Visibility *utils.Visibility
+	// Status is the status a transition moves the issue to
+	Status string
+	// AdjustEstimate is what logging or deleting the worklog does to the
+	// remaining estimate, AdjustBy the reduceBy or increaseBy of a manual
+	// adjustment.
+	AdjustEstimate utils.AdjustEstimate
+	AdjustBy       time.Duration
+}
+
+func (c Change) row() []string {
+	started, duration := "", ""
+	if !c.Started.IsZero() {
+		started = c.Started.Format("2006-01-02 15:04")
+	}
+	if c.TimeSpentSeconds > 0 {
+		duration = utils.FormatDuration(time.Duration(c.TimeSpentSeconds) * time.Second)
+	}
+
+	comment := c.Comment
+	if c.Action == ActionTransition {
+		comment = "to " + c.Status
+	}
+
+	return []string{c.Action, c.Issue, c.Worklog, started, duration, comment}
+}
+
+// recordChange writes change, applied to the worklog id, to j. before is the
+// worklog as it was, nil when it was just created. A journal that cannot be
+// written to does not undo the change, it is only warned about.
+func recordChange(j *journal.Journal, change Change, id string, before *WorklogResponseObject) {
+	entry := journal.Entry{
+		Action:  change.Action,
+		Issue:   change.Issue,
+		Worklog: id,
+	}
+
+	if !change.AdjustEstimate.IsAuto() {
+		entry.AdjustEstimate = string(change.AdjustEstimate)
+	}
+	if string(change.AdjustEstimate) == utils.AdjustEstimateManual {
+		entry.AdjustBySeconds = int(change.AdjustBy / time.Second)
+	}
+
+	if before != nil {
+		entry.Before = &journal.Worklog{
+			Started:          before.Started.Time,
+			TimeSpentSeconds: int(before.TimeSpentSeconds),
+			Comment:          before.CommentMarkdown(),
+			Visibility:       before.Visibility,
+		}
+	}
+
+	if change.Action != ActionDeleteWorklog {
+		entry.After = &journal.Worklog{
+			Started:          change.Started,
+			TimeSpentSeconds: change.TimeSpentSeconds,
+			Comment:          change.Comment,
+			Visibility:       change.Visibility,
+		}
+	}
+
+	if err := j.Record(entry); err != nil {
+		slog.Warn("could not write the change to the journal, it cannot be undone", "action", change.Action, "issue", change.Issue, "worklog", id, "error", err.Error())
+	}
+}
+
+// recordTransition writes change, a transition of its issue from the status
+// from, to j. Like recordChange, a journal that cannot be written to is only
+// warned about.
+func recordTransition(j *journal.Journal, change Change, from string) {
+	entry := journal.Entry{
+		Action:     change.Action,
+		Issue:      change.Issue,
+		FromStatus: from,
+		ToStatus:   change.Status,
+	}
+
+	if err := j.Record(entry); err != nil {
+		slog.Warn("could not write the change to the journal, it cannot be undone", "action", change.Action, "issue", change.Issue, "status", change.Status, "error", err.Error())
+	}
+}
+
+// Preview stands between the mutating calls and Jira. In dry run mode it
+// keeps the changes instead of letting them through, in confirm mode it asks
+// about each change on In first. A nil Preview lets every change through.
+type Preview struct {
+	DryRun  bool
+	Confirm bool
+	In      io.Reader
+
+	mu      sync.Mutex
+	in      *bufio.Reader
+	changes []Change
+	// held counts the changes that were not let through
+	held int
+	// answer is "all" or "quit" once given, applying to every later change
+	answer string
+}
+
+// Approve reports whether change may be sent to Jira.
+func (p *Preview) Approve(change Change) bool {
+	if p == nil || !p.DryRun && !p.Confirm {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.DryRun {
+		p.changes = append(p.changes, change)
+		p.held++
+		return false
+	}
+
+	switch p.answer {
+	case "all":
+		return true
+	case "quit":
+		p.held++
+		return false
+	}
+
+	if p.in == nil {
+		p.in = bufio.NewReader(p.In)
+	}
+
+	utils.DrawRows(changeHeader, [][]string{change.row()})
+	for {
+		fmt.Print("Send to Jira? [y]es, [n]o, [a]ll, [q]uit: ")
+
+		answer, err := p.in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true
+		case "a", "all":
+			p.answer = "all"
+			return true
+		case "q", "quit":
+			p.answer = "quit"
+			p.held++
+			return false
+		case "n", "no":
+			p.held++
+			return false
+		}
+
+		// stdin ran out, nothing more can be approved
+		if err != nil {
+			fmt.Println()
+			p.answer = "quit"
+			p.held++
+			return false
+		}
+	}
+}
+
+// Held is how many changes were kept back so far, by the dry run or by the
+// user declining them.
+func (p *Preview) Held() int {
+	if p == nil {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.held
+}
+
+// Changes are the changes kept in dry run mode.
+func (p *Preview) Changes() []Change {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Change(nil), p.changes...)
+}
+
+var changeHeader = []string{"Action", "Issue", "Worklog", "Started", "Duration", "Comment"}
+
+// DrawChanges prints changes as a table.
+func DrawChanges(changes []Change) {
+	rows := make([][]string, len(changes))
+	for i, change := range changes {
+		rows[i] = change.row()
+	}
+
+	utils.DrawRows(changeHeader, rows)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// Request describes a call to the Jira REST API.
+type Request struct {
+	Method string
+	// Path is relative to the Jira endpoint, i.e "rest/api/3/myself"
+	Path  string
+	Query url.Values
+	// Body is sent as JSON, unless it is an io.Reader or a []byte which are
+	// sent as they are with ContentType.
+	Body        any
+	ContentType string
+	Header      http.Header
+}
+
+// body renders the request body once, so it can be sent again on retries.
+func (r Request) body() ([]byte, string, error) {
+	switch body := r.Body.(type) {
+	case nil:
+		return nil, "", nil
+	case []byte:
+		return body, r.ContentType, nil
+	case io.Reader:
+		data, err := io.ReadAll(body)
+		return data, r.ContentType, err
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			slog.Error("Error while marshaling json", "error", err.Error())
+			return nil, "", err
+		}
+		return data, "application/json", nil
+	}
+}
+
+func (r Request) url(baseURL string) string {
+	u := baseURL + "/" + r.Path
+	if len(r.Query) > 0 {
+		u += "?" + r.Query.Encode()
+	}
+
+	return u
+}
+
+func (r Request) newHTTPRequest(ctx context.Context, baseURL string, body []byte, contentType string) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, r.Method, r.url(baseURL), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range r.Header {
+		request.Header[name] = values
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	return request, nil
+}
+
+// Do sends req and unmarshals a successful response into out, which may be
+// nil when the body is of no interest. The response body is always closed.
+func (js *JiraService) Do(ctx context.Context, req Request, out any) error {
+	response, err := js.MakeJiraRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return decodeJiraResponse(response, out)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 60 * time.Second
+)
+
+// isIdempotent reports whether sending the request twice has the same effect
+// as sending it once. The JQL searches and the worklog lookup by id are POSTs
+// only to carry their body.
+func isIdempotent(method string, urlPath string) bool {
+	if method != http.MethodPost {
+		return true
+	}
+
+	return strings.HasSuffix(urlPath, "/search/jql") || strings.HasSuffix(urlPath, "/search") || strings.HasSuffix(urlPath, "/worklog/list")
+}
+
+// shouldRetry reports whether a response with the given status is worth sending again.
+// Non idempotent requests are only retried when Jira refused them before
+// doing any work, a 502 or 504 could come back for a worklog that was created.
+func shouldRetry(status int, idempotent bool) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// retryDelay picks how long to wait before the next attempt. Jira's
+// Retry-After and X-RateLimit-Reset headers win, otherwise the delay doubles
+// with every attempt with jitter added so parallel clients spread out.
+func retryDelay(response *http.Response, attempt int) time.Duration {
+	if wait, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+		return min(wait, retryMaxDelay)
+	}
+
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		if wait, ok := parseRateLimitReset(response.Header.Get("X-RateLimit-Reset")); ok {
+			return min(wait, retryMaxDelay)
+		}
+	}
+
+	backoff := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// parseRetryAfter understands both forms of Retry-After, seconds and an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// parseRateLimitReset reads the ISO 8601 timestamp Jira Cloud sends in X-RateLimit-Reset.
+func parseRateLimitReset(value string) (time.Duration, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04Z"} {
+		if reset, err := time.Parse(layout, value); err == nil {
+			return max(time.Until(reset), 0), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alinsimion/jira-cli/adf"
+	"github.com/alinsimion/jira-cli/period"
+	"github.com/alinsimion/jira-cli/utils"
+)
+
+// WorklogUpdate holds the new values of a worklog. Zero values and a nil
+// Comment or Visibility leave the worklog as it is, an empty Visibility
+// lifts its restriction.
+type WorklogUpdate struct {
+	Started          time.Time
+	TimeSpentSeconds int
+	Comment          *string
+	Visibility       *utils.Visibility
+}
+
+// IsZero reports whether the update changes nothing.
+func (u WorklogUpdate) IsZero() bool {
+	return u.Started.IsZero() && u.TimeSpentSeconds == 0 && u.Comment == nil && u.Visibility == nil
+}
+
+func (u WorklogUpdate) change(issue string, id string) Change {
+	change := Change{
+		Action:           ActionUpdateWorklog,
+		Issue:            issue,
+		Worklog:          id,
+		Started:          u.Started,
+		TimeSpentSeconds: u.TimeSpentSeconds,
+	}
+
+	if !u.Visibility.IsZero() {
+		change.Visibility = u.Visibility
+	}
+
+	if u.Comment != nil {
+		change.Comment = *u.Comment
+	}
+
+	return change
+}
+
+// CommentText is the plain text of the worklog's comment.
+func (w WorklogResponseObject) CommentText() string {
+	return commentText(w.Comment)
+}
+
+// CommentMarkdown is the worklog's comment written back as Markdown, the
+// way it is given to LogWork and UpdateWorklog.
+func (w WorklogResponseObject) CommentMarkdown() string {
+	if text, ok := w.Comment.(string); ok {
+		return text
+	}
+
+	return adf.ToMarkdown(w.Comment)
+}
+
+// Duration is the time spent, going by timeSpentSeconds rather than the
+// text Jira wrote it as.
+func (w WorklogResponseObject) Duration() time.Duration {
+	return time.Duration(w.TimeSpentSeconds) * time.Second
+}
+
+// GetWorklog looks a worklog of any issue up by its id.
+func (js *JiraService) GetWorklog(ctx context.Context, id string) (WorklogResponseObject, error) {
+	number, err := strconv.Atoi(id)
+	if err != nil {
+		return WorklogResponseObject{}, fmt.Errorf("worklog id %q must be a number", id)
+	}
+
+	var worklogs []WorklogResponseObject
+
+	req := Request{Method: http.MethodPost, Path: js.api(ctx, "worklog/list"), Body: map[string]any{"ids": []int{number}}}
+	if err := js.Do(ctx, req, &worklogs); err != nil {
+		return WorklogResponseObject{}, err
+	}
+
+	if len(worklogs) == 0 {
+		return WorklogResponseObject{}, fmt.Errorf("worklog %s: %w", id, ErrNotFound)
+	}
+
+	return worklogs[0], nil
+}
+
+func (js *JiraService) FindWorklogs(ctx context.Context, issue string, days period.Range) ([]WorklogResponseObject, error) {
+	return findWorklogs(ctx, js, js.User, issue, days)
+}
+
+// findWorklogs returns the worklogs user started on issue during days,
+// through the given client. It is shared by every JiraClient.
+func findWorklogs(ctx context.Context, client JiraClient, user JiraUser, issue string, days period.Range) ([]WorklogResponseObject, error) {
+	response, err := client.GetWorkLogsForIssue(ctx, issue, rangeQuery(days))
+	if err != nil {
+		return nil, err
+	}
+
+	worklogs := []WorklogResponseObject{}
+	for _, worklog := range response.WorkLogs {
+		if days.Contains(worklog.Started.Time) && authoredBy(worklog, user) {
+			worklogs = append(worklogs, worklog)
+		}
+	}
+
+	return worklogs, nil
+}
+
+// UpdateWorklog changes the worklog id of issue, issue being its key or its id.
+func (js *JiraService) UpdateWorklog(ctx context.Context, issue string, id string, update WorklogUpdate) error {
+	change := update.change(issue, id)
+	if !js.cfg.Preview.Approve(change) {
+		return nil
+	}
+
+	before, err := js.journaledWorklog(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]any{}
+	if !update.Started.IsZero() {
+		payload["started"] = update.Started.Format("2006-01-02T15:04:05.000-0700")
+	}
+	if update.TimeSpentSeconds > 0 {
+		payload["timeSpentSeconds"] = update.TimeSpentSeconds
+	}
+	if update.Comment != nil {
+		payload["comment"] = js.comment(ctx, *update.Comment)
+	}
+	switch {
+	case update.Visibility == nil:
+	case update.Visibility.IsZero():
+		// null lets anyone see the worklog again
+		payload["visibility"] = nil
+	default:
+		payload["visibility"] = update.Visibility
+	}
+
+	var worklogResponse WorklogResponseObject
+
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s/worklog/%s", issue, id))
+	if err := js.Do(ctx, Request{Method: http.MethodPut, Path: urlPath, Body: payload}, &worklogResponse); err != nil {
+		return err
+	}
+
+	fmt.Printf("Worklog %s updated: %s of work on %s\n", id, utils.FormatDuration(worklogResponse.Duration()), worklogResponse.Started)
+
+	recordChange(js.cfg.Journal, change, id, before)
+
+	return nil
+}
+
+// DeleteWorklog removes the worklog id of issue, issue being its key or its
+// id, adjusting the remaining estimate of issue the way adjust asks.
+func (js *JiraService) DeleteWorklog(ctx context.Context, issue string, id string, adjust DeleteAdjustment) error {
+	change := Change{Action: ActionDeleteWorklog, Issue: issue, Worklog: id, AdjustEstimate: adjust.AdjustEstimate, AdjustBy: adjust.IncreaseBy}
+	if !js.cfg.Preview.Approve(change) {
+		return nil
+	}
+
+	before, err := js.journaledWorklog(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	urlPath := js.api(ctx, fmt.Sprintf("issue/%s/worklog/%s", issue, id))
+	if err := js.Do(ctx, Request{Method: http.MethodDelete, Path: urlPath, Query: adjust.values()}, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Worklog %s deleted\n", id)
+
+	recordChange(js.cfg.Journal, change, id, before)
+
+	return nil
+}
+
+// journaledWorklog fetches the worklog id before it is changed, for the
+// journal to be able to restore it. It is nil without a journal.
+func (js *JiraService) journaledWorklog(ctx context.Context, id string) (*WorklogResponseObject, error) {
+	if js.cfg.Journal == nil {
+		return nil, nil
+	}
+
+	worklog, err := js.GetWorklog(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &worklog, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alinsimion/jira-cli/period"
+)
+
+// fetchIssueWorklogs fills in the worklogs of every issue, asking Jira for at
+// most concurrency issues at a time. Issues keep their order whatever order
+// the answers come back in. Issues that failed are left without worklogs and
+// their errors are returned joined together.
+func fetchIssueWorklogs(ctx context.Context, client JiraClient, issues []Issue, query WorklogQuery, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(issues))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(issues)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				workLog, err := client.GetWorkLogsForIssue(ctx, issues[i].Key, query)
+				if err != nil {
+					errs[i] = fmt.Errorf("%s: %w", issues[i].Key, err)
+					continue
+				}
+				issues[i].Worklogs = workLog.WorkLogs
+			}
+		}()
+	}
+
+	for i := range issues {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// timesheet sorts the time user logged during days by day, in the
+// time.DateOnly format, and by issue key.
+func timesheet(ctx context.Context, client JiraClient, user JiraUser, days period.Range, concurrency int) (map[string]map[string]time.Duration, error) {
+	issues, err := client.GetUsersIssuesFromPeriod(ctx, days.From, days.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fetchIssueWorklogs(ctx, client, issues, rangeQuery(days), concurrency); err != nil {
+		return nil, err
+	}
+
+	sheet := map[string]map[string]time.Duration{}
+	for _, issue := range issues {
+		for _, worklog := range issue.Worklogs {
+			if !days.Contains(worklog.Started.Time) || !authoredBy(worklog, user) {
+				continue
+			}
+
+			day := worklog.Started.Local().Format(time.DateOnly)
+			if _, ok := sheet[day]; !ok {
+				sheet[day] = map[string]time.Duration{}
+			}
+			sheet[day][issue.Key] += worklog.Duration()
+		}
+	}
+
+	return sheet, nil
+}
+
+// loggedTime sums, per day of days in the time.DateOnly format, the time
+// user logged on any issue.
+func loggedTime(ctx context.Context, client JiraClient, user JiraUser, days period.Range, concurrency int) (map[string]time.Duration, error) {
+	sheet, err := timesheet(ctx, client, user, days, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	logged := map[string]time.Duration{}
+	for day, issues := range sheet {
+		for _, spent := range issues {
+			logged[day] += spent
+		}
+	}
+
+	return logged, nil
+}
+
+// authoredBy reports whether user wrote worklog, going by the account id on
+// Cloud and by the user name on Server, which has no account ids.
+func authoredBy(worklog WorklogResponseObject, user JiraUser) bool {
+	if user.AccountId != "" {
+		return worklog.Author.AccountId == user.AccountId
+	}
+
+	return worklog.Author.Name == user.Name
+}
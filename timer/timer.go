@@ -0,0 +1,189 @@
+// Package timer tracks the time spent on an issue between a start and a stop,
+// in a local JSON file shared by every shell.
+package timer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Rounding string
+
+const (
+	// RoundingUp rounds the tracked time up to the next step.
+	RoundingUp string = "up"
+	// RoundingNearest rounds the tracked time to the closest step.
+	RoundingNearest string = "nearest"
+	// RoundingDown rounds the tracked time down to the previous step.
+	RoundingDown string = "down"
+)
+
+func (e *Rounding) String() string {
+	return string(*e)
+}
+
+func (e *Rounding) Set(v string) error {
+	switch v {
+	case RoundingUp, RoundingNearest, RoundingDown:
+		*e = Rounding(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s", []string{RoundingUp, RoundingNearest, RoundingDown})
+	}
+}
+
+func (e *Rounding) Type() string {
+	return "Rounding"
+}
+
+// Round rounds d, to the second, to a multiple of step. A step under a
+// minute rounds to the minute, the smallest amount Jira accepts.
+func (e Rounding) Round(d time.Duration, step time.Duration) time.Duration {
+	if step < time.Minute {
+		step = time.Minute
+	}
+
+	d = d.Truncate(time.Second)
+
+	switch string(e) {
+	case RoundingDown:
+		return d.Truncate(step)
+	case RoundingNearest:
+		return d.Round(step)
+	default:
+		rounded := d.Truncate(step)
+		if rounded < d {
+			rounded += step
+		}
+		return rounded
+	}
+}
+
+// Timer is the time being tracked on an issue.
+type Timer struct {
+	Issue   string    `json:"issue"`
+	Comment string    `json:"comment"`
+	Started time.Time `json:"started"`
+	// Elapsed is the time tracked until the last pause
+	Elapsed time.Duration `json:"elapsed"`
+	// Resumed is when the timer last started counting, zero while it is paused
+	Resumed time.Time `json:"resumed"`
+}
+
+// Start starts a timer on issue at now.
+func Start(issue string, comment string, now time.Time) *Timer {
+	return &Timer{
+		Issue:   issue,
+		Comment: comment,
+		Started: now,
+		Resumed: now,
+	}
+}
+
+// Paused reports whether the timer is paused.
+func (t *Timer) Paused() bool {
+	return t.Resumed.IsZero()
+}
+
+// Tracked is the time tracked until now, the pauses left out.
+func (t *Timer) Tracked(now time.Time) time.Duration {
+	if t.Paused() {
+		return t.Elapsed
+	}
+
+	return t.Elapsed + now.Sub(t.Resumed)
+}
+
+// Pause stops the timer from counting at now.
+func (t *Timer) Pause(now time.Time) error {
+	if t.Paused() {
+		return fmt.Errorf("the timer on %s is paused already", t.Issue)
+	}
+
+	t.Elapsed = t.Tracked(now)
+	t.Resumed = time.Time{}
+
+	return nil
+}
+
+// Resume makes a paused timer count again from now.
+func (t *Timer) Resume(now time.Time) error {
+	if !t.Paused() {
+		return fmt.Errorf("the timer on %s is running already", t.Issue)
+	}
+
+	t.Resumed = now
+
+	return nil
+}
+
+func (t *Timer) String() string {
+	state := "running"
+	if t.Paused() {
+		state = "paused"
+	}
+
+	return fmt.Sprintf("%s, %s, started %s", t.Issue, state, t.Started.Local().Format("2006-01-02 15:04"))
+}
+
+// State is the timer saved in a file. Its Timer is nil when none is running.
+type State struct {
+	path  string
+	Timer *Timer
+}
+
+// DefaultPath is where the timer is kept unless told otherwise.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "jira-cli", "timer.json")
+}
+
+// Open reads the timer saved at path. A missing file holds no timer.
+func Open(path string) (*State, error) {
+	state := &State{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &state.Timer); err != nil {
+		return nil, fmt.Errorf("reading the timer from %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// Save writes the timer back to the file it was read from, removing the
+// file when there is no timer left.
+func (s *State) Save() error {
+	if s.Timer == nil {
+		err := os.Remove(s.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.Timer, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o644)
+}
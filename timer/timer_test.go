@@ -0,0 +1,43 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRound(t *testing.T) {
+	const step = 15 * time.Minute
+
+	tests := []struct {
+		name string
+		d    time.Duration
+		step time.Duration
+		// the time rounded up, to the nearest step and down
+		up, nearest, down time.Duration
+	}{
+		{name: "on the step", d: 30 * time.Minute, step: step, up: 30 * time.Minute, nearest: 30 * time.Minute, down: 30 * time.Minute},
+		{name: "under a second past the step", d: 30*time.Minute + 500*time.Millisecond, step: step, up: 30 * time.Minute, nearest: 30 * time.Minute, down: 30 * time.Minute},
+		{name: "a second past the step", d: 30*time.Minute + time.Second, step: step, up: 45 * time.Minute, nearest: 30 * time.Minute, down: 30 * time.Minute},
+		{name: "just below half", d: 37*time.Minute + 29*time.Second, step: step, up: 45 * time.Minute, nearest: 30 * time.Minute, down: 30 * time.Minute},
+		{name: "half", d: 37*time.Minute + 30*time.Second, step: step, up: 45 * time.Minute, nearest: 45 * time.Minute, down: 30 * time.Minute},
+		{name: "just above half", d: 37*time.Minute + 31*time.Second, step: step, up: 45 * time.Minute, nearest: 45 * time.Minute, down: 30 * time.Minute},
+		{name: "nothing", d: 0, step: step},
+		{name: "zero step rounds to the minute", d: 90 * time.Second, up: 2 * time.Minute, nearest: 2 * time.Minute, down: time.Minute},
+		{name: "step under a minute", d: 89 * time.Second, step: 10 * time.Second, up: 2 * time.Minute, nearest: time.Minute, down: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for rounding, want := range map[string]time.Duration{RoundingUp: tt.up, RoundingNearest: tt.nearest, RoundingDown: tt.down} {
+				if got := Rounding(rounding).Round(tt.d, tt.step); got != want {
+					t.Errorf("rounding %s %s by %s = %s, want %s", rounding, tt.d, tt.step, got, want)
+				}
+			}
+
+			// up unless told otherwise
+			if got := Rounding("").Round(tt.d, tt.step); got != tt.up {
+				t.Errorf("rounding %s by %s = %s, want %s", tt.d, tt.step, got, tt.up)
+			}
+		})
+	}
+}
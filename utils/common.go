@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alinsimion/jira-cli/period"
 	"github.com/spf13/cobra"
 )
 
@@ -30,6 +31,14 @@ func DumpDotEnv() error {
 				return err
 			}
 		}
+
+		for _, varName := range OPTIONAL_ENV_VAR_NAMES {
+			_, err = file.WriteString(fmt.Sprintf("# %s=\n", varName))
+			if err != nil {
+				slog.Error("Error writing to .env file", "error", err.Error())
+				return err
+			}
+		}
 		return nil
 	} else {
 		return errors.New("file already exists")
@@ -37,6 +46,66 @@ func DumpDotEnv() error {
 
 }
 
+// GetEnvString reads the environment variable name, falling back to def when it is empty.
+func GetEnvString(name string, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return def
+}
+
+// GetEnvDuration reads a duration such as "30s" from the environment variable
+// name, falling back to def when it is empty or malformed.
+func GetEnvDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("could not parse duration, using default", "variable", name, "value", value, "default", def)
+		return def
+	}
+
+	return d
+}
+
+// GetEnvInt reads an integer from the environment variable name, falling back
+// to def when it is empty or malformed.
+func GetEnvInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("could not parse number, using default", "variable", name, "value", value, "default", def)
+		return def
+	}
+
+	return i
+}
+
+// GetEnvFloat reads a number from the environment variable name, falling back
+// to def when it is empty or malformed.
+func GetEnvFloat(name string, def float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("could not parse number, using default", "variable", name, "value", value, "default", def)
+		return def
+	}
+
+	return f
+}
+
 type CustomTime struct {
 	time.Time
 }
@@ -58,59 +127,263 @@ func (ct *CustomTime) UnmarshalJSON(b []byte) error {
 type LogWorkParams struct {
 	Date      string
 	IssueKey  string
-	TimeSpent float32
-	Period    Period
-	Message   string
+	TimeSpent time.Duration
+	Period    period.Period
+	// From and To bound an explicit range of days, both included
+	From    string
+	To      string
+	Message string
+	// Mode tells what to do on days that already have work logged, LogModeAdd when empty
+	Mode LogMode
+	// Started, when set, is the exact start of the worklog and takes over Date
+	Started time.Time
+	// ScheduledTime makes TimeSpent a default, the days with hours of their
+	// own in the work schedule get those instead
+	ScheduledTime bool
+	// AllowWeekend logs work on the days off of the work schedule too
+	AllowWeekend bool
+	// AdjustEstimate tells what happens to the remaining estimate of the
+	// issue, AdjustEstimateAuto when empty. NewEstimate, which may be zero,
+	// goes with AdjustEstimateNew and ReduceBy with AdjustEstimateManual.
+	AdjustEstimate AdjustEstimate
+	NewEstimate    *time.Duration
+	ReduceBy       time.Duration
+	// Strict refuses worklogs going over the remaining estimate instead of
+	// warning about them
+	Strict bool
+	// Visibility, when set, restricts who can see the worklog
+	Visibility Visibility
 }
 
-func NewLogWorkParams(cmd *cobra.Command) LogWorkParams {
+func NewLogWorkParams(cmd *cobra.Command) (LogWorkParams, error) {
 	date, _ := cmd.Flags().GetString("date")
 	issueKey, _ := cmd.Flags().GetString("issueKey")
-	timeSpent, _ := cmd.Flags().GetFloat32("time")
-	period := PeriodEnum
+	timeText, _ := cmd.Flags().GetString("time")
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	message, _ := cmd.Flags().GetString("message")
+	allowWeekend, _ := cmd.Flags().GetBool("allow-weekend")
+	strict, _ := cmd.Flags().GetBool("strict")
+
+	timeSpent, err := ParseDuration(timeText)
+	if err != nil {
+		return LogWorkParams{}, err
+	}
 
-	return LogWorkParams{
-		Date:      date,
-		IssueKey:  issueKey,
-		TimeSpent: timeSpent,
-		Period:    period,
+	var newEstimate *time.Duration
+	if cmd.Flags().Changed("new-estimate") {
+		text, _ := cmd.Flags().GetString("new-estimate")
+		estimate, err := ParseDuration(text)
+		if err != nil {
+			return LogWorkParams{}, fmt.Errorf("--new-estimate: %w", err)
+		}
+		newEstimate = &estimate
 	}
+
+	var visibility Visibility
+	if flag := cmd.Flags().Lookup("visibility"); flag != nil && flag.Changed {
+		if err := visibility.Set(flag.Value.String()); err != nil {
+			return LogWorkParams{}, fmt.Errorf("--visibility: %w", err)
+		}
+	}
+
+	var reduceBy time.Duration
+	if cmd.Flags().Changed("reduce-by") {
+		text, _ := cmd.Flags().GetString("reduce-by")
+		if reduceBy, err = ParseDuration(text); err != nil {
+			return LogWorkParams{}, fmt.Errorf("--reduce-by: %w", err)
+		}
+	}
+
+	return LogWorkParams{
+		Date:           date,
+		IssueKey:       issueKey,
+		TimeSpent:      timeSpent,
+		Period:         GetPeriodFlag(cmd),
+		From:           from,
+		To:             to,
+		Message:        message,
+		Mode:           LogMode(cmd.Flags().Lookup("mode").Value.String()),
+		ScheduledTime:  !cmd.Flags().Changed("time"),
+		AllowWeekend:   allowWeekend,
+		AdjustEstimate: AdjustEstimate(cmd.Flags().Lookup("adjust-estimate").Value.String()),
+		NewEstimate:    newEstimate,
+		ReduceBy:       reduceBy,
+		Strict:         strict,
+		Visibility:     visibility,
+	}, nil
 }
 
 func (p *LogWorkParams) Validate() error {
+	if p.IssueKey == "" || p.TimeSpent <= 0 {
+		return errors.New("bad flag combination, an issue key and a positive time are required")
+	}
+
+	// logwork -i SAV-2321 -t 2h --adjust-estimate new --new-estimate 1d
+	// logwork -i SAV-2321 -t 2h --adjust-estimate manual --reduce-by 1h
+	if (p.AdjustEstimate == AdjustEstimate(AdjustEstimateNew)) != (p.NewEstimate != nil) {
+		return errors.New("bad flag combination, --new-estimate goes with --adjust-estimate new, which requires it")
+	}
+
+	if (p.AdjustEstimate == AdjustEstimate(AdjustEstimateManual)) != (p.ReduceBy > 0) {
+		return errors.New("bad flag combination, --reduce-by goes with --adjust-estimate manual, which requires a positive one")
+	}
+
 	// logwork -d 10/10/2024 -t 6 -i SAV-2321
-	if p.Date != "" && p.TimeSpent != 0 && p.IssueKey != "" {
-		return nil
+	// logwork -d "last friday" -t 6 -i SAV-2321
+	if !p.HasRange() {
+		_, err := ParseDate(p.Date)
+		return err
 	}
 
-	// logwork -i SAV-2321 -p lastweek
-	if p.IssueKey != "" && p.Period != "" {
-		return nil
+	if p.Date != TODAY_FLAG {
+		return errors.New("bad flag combination, --date cannot be combined with --period, --from or --to")
 	}
 
-	return errors.New("bad flag combination")
+	// logwork -i SAV-2321 --period lastweek
+	// logwork -i SAV-2321 --from 01/07/2024 --to 12/07/2024
+	_, err := RangeFromFlags(p.Period, p.From, p.To, time.Now())
+	return err
 }
 
-func GetSimpleDateFormat(timestamp time.Time) string {
-	return fmt.Sprintf("%d/%d/%d", timestamp.Day(), timestamp.Month(), timestamp.Year())
+// FillParams selects the days fill completes and how the missing hours are
+// shared between issues.
+type FillParams struct {
+	Period period.Period
+	From   string
+	To     string
+	// Target is the time every working day should have logged
+	Target time.Duration
+	// ScheduledTarget makes Target a default, the days with hours of their
+	// own in the work schedule get those instead
+	ScheduledTarget bool
+	// Issues share the missing hours equally, Weights share them by weight.
+	// With neither, the issues share them like the work already logged on
+	// them the same week.
+	Issues  []string
+	Weights map[string]float32
+	Message string
+}
+
+func NewFillParams(cmd *cobra.Command) (FillParams, error) {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	targetText, _ := cmd.Flags().GetString("target")
+	issues, _ := cmd.Flags().GetStringSlice("issues")
+	weightFlags, _ := cmd.Flags().GetStringToString("weights")
+	message, _ := cmd.Flags().GetString("message")
+
+	target, err := ParseDuration(targetText)
+	if err != nil {
+		return FillParams{}, err
+	}
+
+	weights := map[string]float32{}
+	for issue, value := range weightFlags {
+		weight, err := strconv.ParseFloat(value, 32)
+		if err != nil || weight < 0 {
+			return FillParams{}, fmt.Errorf("weight %q of %s must be a positive number", value, issue)
+		}
+		weights[issue] = float32(weight)
+	}
+
+	return FillParams{
+		Period:          GetPeriodFlag(cmd),
+		From:            from,
+		To:              to,
+		Target:          target,
+		ScheduledTarget: !cmd.Flags().Changed("target"),
+		Issues:          issues,
+		Weights:         weights,
+		Message:         message,
+	}, nil
+}
+
+func (p *FillParams) Validate() error {
+	if p.Target <= 0 {
+		return errors.New("bad flag combination, a positive target is required")
+	}
+
+	if len(p.Issues) > 0 && len(p.Weights) > 0 {
+		return errors.New("bad flag combination, --issues cannot be combined with --weights")
+	}
+
+	if p.Period == "" && p.From == "" {
+		return errors.New("bad flag combination, --period or --from is required")
+	}
+
+	_, err := RangeFromFlags(p.Period, p.From, p.To, time.Now())
+	return err
+}
+
+// GetPeriodFlag reads the --period flag of cmd, empty when it has none.
+func GetPeriodFlag(cmd *cobra.Command) period.Period {
+	if flag := cmd.Flags().Lookup("period"); flag != nil {
+		return period.Period(flag.Value.String())
+	}
+
+	return ""
+}
+
+// Seconds is TimeSpent in whole seconds.
+func (p *LogWorkParams) Seconds() int {
+	return int(p.TimeSpent.Round(time.Second) / time.Second)
+}
+
+// ChecksLogged reports whether the work already logged must be looked at
+// before logging more.
+func (p *LogWorkParams) ChecksLogged() bool {
+	return p.Mode != "" && p.Mode != LogMode(LogModeAdd)
 }
 
-func DaysInMonth(m time.Month) []int {
-	t := time.Date(time.Now().Year(), m, 32, 0, 0, 0, 0, time.UTC)
+// HasRange reports whether work is logged over a period or a range of days
+// rather than on a single date.
+func (p *LogWorkParams) HasRange() bool {
+	return p.Period != "" || p.From != "" || p.To != ""
+}
 
-	daysInMonth := 32 - t.Day()
-	days := make([]int, daysInMonth)
+// RangeFromFlags resolves the days selected by a --period or by --from and
+// --to at now. --to defaults to today.
+func RangeFromFlags(p period.Period, from string, to string, now time.Time) (period.Range, error) {
+	if p != "" {
+		if from != "" || to != "" {
+			return period.Range{}, errors.New("bad flag combination, --period cannot be combined with --from or --to")
+		}
 
-	for i := range days {
-		days[i] = i + 1
+		return period.Resolve(p, now)
+	}
+
+	if from == "" {
+		return period.Range{}, errors.New("bad flag combination, --to needs --from")
+	}
+
+	fromDate, err := ParseDateAt(from, now)
+	if err != nil {
+		return period.Range{}, err
+	}
+
+	toDate := now
+	if to != "" {
+		toDate, err = ParseDateAt(to, now)
+		if err != nil {
+			return period.Range{}, err
+		}
 	}
 
-	return days
+	return period.Between(fromDate, toDate)
+}
+
+// GetSimpleDateFormat writes the date of timestamp as yyyy-mm-dd, which
+// ParseDate reads whatever the date order.
+func GetSimpleDateFormat(timestamp time.Time) string {
+	return timestamp.Format(time.DateOnly)
 }
 
 // ----
 
-func DrawTable(table map[string]map[string][]string) {
+// DrawTable prints table with a row per key, sorted, except for lastRows
+// which are drawn after the others in the given order.
+func DrawTable(table map[string]map[string][]string, lastRows ...string) {
 	columnHeight := make(map[string]int)
 	colWidth := 0
 	days := []string{}
@@ -126,6 +399,10 @@ func DrawTable(table map[string]map[string][]string) {
 				}
 			}
 
+			if colWidth < len(day) {
+				colWidth = len(day)
+			}
+
 			days = append(days, day)
 		}
 	}
@@ -137,7 +414,8 @@ func DrawTable(table map[string]map[string][]string) {
 	printRow("Issue Key", days, colWidth)
 	printBorder(len(days), colWidth)
 
-	for issueKey, daysMap := range table {
+	for _, issueKey := range rowKeys(table, lastRows) {
+		daysMap := table[issueKey]
 
 		for i := 0; i < columnHeight[issueKey]; i++ {
 			tempDays := []string{}
@@ -161,6 +439,29 @@ func DrawTable(table map[string]map[string][]string) {
 	}
 }
 
+func rowKeys(table map[string]map[string][]string, lastRows []string) []string {
+	last := map[string]bool{}
+	for _, key := range lastRows {
+		last[key] = true
+	}
+
+	keys := []string{}
+	for key := range table {
+		if !last[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range lastRows {
+		if _, ok := table[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
 func uniqueSlice(input []string) []string {
 	uniqueMap := make(map[string]bool)
 	var unique []string
@@ -229,3 +530,44 @@ func printBorder(numCols int, colWidth int) {
 	}
 	fmt.Println()
 }
+
+// DrawRows prints rows under header, every column as wide as its widest cell.
+func DrawRows(header []string, rows [][]string) {
+	// multi-line cells, such as Markdown comments, are drawn on one line
+	flat := make([][]string, len(rows))
+	for i, row := range rows {
+		flat[i] = make([]string, len(row))
+		for j, cell := range row {
+			flat[i][j] = strings.Join(strings.Fields(cell), " ")
+		}
+	}
+	rows = flat
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	border := "+"
+	for _, width := range widths {
+		border += strings.Repeat("-", width+2) + "+"
+	}
+
+	line := func(row []string) {
+		cells := make([]string, len(widths))
+		for i, width := range widths {
+			cells[i] = fmt.Sprintf("%-*s", width, row[i])
+		}
+		fmt.Println("| " + strings.Join(cells, " | ") + " |")
+	}
+
+	fmt.Println(border)
+	line(header)
+	fmt.Println(border)
+	for _, row := range rows {
+		line(row)
+	}
+	fmt.Println(border)
+}
@@ -5,8 +5,35 @@ const (
 	JIRA_ENDPOINT   = "JIRA_ENDPOINT"
 	JIRA_USER_EMAIL = "JIRA_USER_EMAIL"
 
+	JIRA_CONNECT_TIMEOUT = "JIRA_CONNECT_TIMEOUT"
+	JIRA_READ_TIMEOUT    = "JIRA_READ_TIMEOUT"
+	JIRA_MAX_ATTEMPTS    = "JIRA_MAX_ATTEMPTS"
+	JIRA_CONCURRENCY     = "JIRA_CONCURRENCY"
+	JIRA_DEPLOYMENT      = "JIRA_DEPLOYMENT"
+	JIRA_AUTH            = "JIRA_AUTH"
+
+	JIRA_DATE_ORDER = "JIRA_DATE_ORDER"
+
+	JIRA_WORK_WEEK  = "JIRA_WORK_WEEK"
+	JIRA_WORK_START = "JIRA_WORK_START"
+
+	JIRA_HOURS_PER_DAY = "JIRA_HOURS_PER_DAY"
+	JIRA_DAYS_PER_WEEK = "JIRA_DAYS_PER_WEEK"
+
+	JIRA_HOLIDAYS_COUNTRY = "JIRA_HOLIDAYS_COUNTRY"
+	JIRA_HOLIDAYS_FILE    = "JIRA_HOLIDAYS_FILE"
+
+	JIRA_ABSENCE_ISSUE = "JIRA_ABSENCE_ISSUE"
+	JIRA_ABSENCE_FILE  = "JIRA_ABSENCE_FILE"
+
+	JIRA_JOURNAL_FILE = "JIRA_JOURNAL_FILE"
+
+	JIRA_TIMER_FILE     = "JIRA_TIMER_FILE"
+	JIRA_TIMER_ROUND    = "JIRA_TIMER_ROUND"
+	JIRA_TIMER_ROUNDING = "JIRA_TIMER_ROUNDING"
+
 	TODAY_FLAG       = "today"
-	DEFAULT_LOG_TIME = 6
+	DEFAULT_LOG_TIME = "6h"
 )
 
 var (
@@ -15,6 +42,29 @@ var (
 	ENV_VAR_NAMES = map[string]bool{
 		JIRA_API_KEY:    true,
 		JIRA_ENDPOINT:   true,
-		JIRA_USER_EMAIL: true,
+		JIRA_USER_EMAIL: false, // personal access tokens on Jira Server need no email
+	}
+
+	// OPTIONAL_ENV_VAR_NAMES tune the cli and have sensible defaults when left empty
+	OPTIONAL_ENV_VAR_NAMES = []string{
+		JIRA_CONNECT_TIMEOUT,
+		JIRA_READ_TIMEOUT,
+		JIRA_MAX_ATTEMPTS,
+		JIRA_CONCURRENCY,
+		JIRA_DEPLOYMENT,
+		JIRA_AUTH,
+		JIRA_DATE_ORDER,
+		JIRA_WORK_WEEK,
+		JIRA_WORK_START,
+		JIRA_HOURS_PER_DAY,
+		JIRA_DAYS_PER_WEEK,
+		JIRA_HOLIDAYS_COUNTRY,
+		JIRA_HOLIDAYS_FILE,
+		JIRA_ABSENCE_ISSUE,
+		JIRA_ABSENCE_FILE,
+		JIRA_JOURNAL_FILE,
+		JIRA_TIMER_FILE,
+		JIRA_TIMER_ROUND,
+		JIRA_TIMER_ROUNDING,
 	}
 )
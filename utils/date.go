@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateOrder is the order of the day, month and year in numeric dates such
+// as 12/07/2024. Dates starting with a four digit year are always read as
+// yyyy-mm-dd.
+type DateOrder string
+
+const (
+	DateOrderDMY string = "dmy"
+	DateOrderMDY string = "mdy"
+	DateOrderYMD string = "ymd"
+)
+
+var (
+	DateOrderEnum = DateOrder(DateOrderDMY)
+)
+
+func (e *DateOrder) String() string {
+	return string(*e)
+}
+
+func (e *DateOrder) Set(v string) error {
+	switch v {
+	case DateOrderDMY, DateOrderMDY, DateOrderYMD:
+		*e = DateOrder(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s", []string{DateOrderDMY, DateOrderMDY, DateOrderYMD})
+	}
+}
+
+func (e *DateOrder) Type() string {
+	return "DateOrder"
+}
+
+// Layout is how a date is written in the order, i.e dd/mm/yyyy.
+func (e DateOrder) Layout() string {
+	switch string(e) {
+	case DateOrderMDY:
+		return "mm/dd/yyyy"
+	case DateOrderYMD:
+		return "yyyy/mm/dd"
+	default:
+		return "dd/mm/yyyy"
+	}
+}
+
+var (
+	dateOffset  = regexp.MustCompile(`^([+-]\d+)([dw])$`)
+	numericDate = regexp.MustCompile(`^(\d+)([/.-])(\d+)(?:([/.-])(\d+))?$`)
+)
+
+// ParseDate reads a date written in the order of DateOrderEnum, such as
+// 12/07/2024, or 12/07 for this year, in the ISO 8601 yyyy-mm-dd format, or
+// relative to today: today, yesterday, tomorrow, a weekday such as friday
+// for the last one up to today, last friday for the one of the week before,
+// or an offset in days or weeks such as -3d or +1w.
+func ParseDate(value string) (time.Time, error) {
+	return ParseDateAt(value, time.Now())
+}
+
+// ParseDateAt reads value like ParseDate, with relative dates counted from now.
+func ParseDateAt(value string, now time.Time) (time.Time, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(value), " "))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	switch text {
+	case TODAY_FLAG:
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if match := dateOffset.FindStringSubmatch(text); match != nil {
+		count, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse date %q, the offset is too large", value)
+		}
+		if match[2] == "w" {
+			count *= 7
+		}
+		return today.AddDate(0, 0, count), nil
+	}
+
+	if name, last := strings.CutPrefix(text, "last "); last || !strings.ContainsAny(text, "0123456789") {
+		weekday, err := ParseWeekday(name)
+		if err != nil {
+			return time.Time{}, dateError(value)
+		}
+
+		// the last one up to today, or the one of the week before today's week
+		back := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if last {
+			sinceMonday := (int(today.Weekday()) + 6) % 7
+			back = sinceMonday + 7 - (int(weekday)+6)%7
+		}
+		return today.AddDate(0, 0, -back), nil
+	}
+
+	match := numericDate.FindStringSubmatch(text)
+	if match == nil || match[4] != "" && match[4] != match[2] {
+		return time.Time{}, dateError(value)
+	}
+
+	first, _ := strconv.Atoi(match[1])
+	second, _ := strconv.Atoi(match[3])
+	third, _ := strconv.Atoi(match[5])
+	dayFirst := DateOrderEnum == DateOrder(DateOrderDMY)
+
+	var day, month, year int
+	switch {
+	// a four digit year first is yyyy-mm-dd whatever the order
+	case len(match[1]) == 4 && match[5] != "":
+		year, month, day = first, second, third
+	// without a year, the date is this year's
+	case match[5] == "" && dayFirst:
+		year, month, day = today.Year(), second, first
+	case match[5] == "":
+		year, month, day = today.Year(), first, second
+	case len(match[5]) != 4:
+		return time.Time{}, fmt.Errorf("could not parse date %q, the year must have four digits", value)
+	case dayFirst:
+		year, month, day = third, second, first
+	case DateOrderEnum == DateOrder(DateOrderMDY):
+		year, month, day = third, first, second
+	default:
+		return time.Time{}, dateError(value)
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	if date.Day() != day || int(date.Month()) != month || date.Year() != year {
+		return time.Time{}, fmt.Errorf("%q is not a valid date", value)
+	}
+
+	return date, nil
+}
+
+func dateError(value string) error {
+	return fmt.Errorf("could not parse date %q, expected %s, yyyy-mm-dd, today, yesterday, a weekday like friday or last friday, or an offset like -3d", value, DateOrderEnum.Layout())
+}
+
+// ParseWeekday reads the name of a day of the week, in full or from its
+// first three letters on, i.e mon, tues or wednesday.
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(name)
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if len(name) >= 3 && strings.HasPrefix(strings.ToLower(day.String()), name) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown day %q, expected one of mon, tue, wed, thu, fri, sat or sun", name)
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateAt(t *testing.T) {
+	// a Wednesday afternoon
+	now := time.Date(2024, time.March, 6, 15, 30, 0, 0, time.Local)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name    string
+		order   string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "today", value: "today", want: date(time.March, 6)},
+		{name: "yesterday", value: " Yesterday ", want: date(time.March, 5)},
+		{name: "tomorrow", value: "tomorrow", want: date(time.March, 7)},
+		{name: "days back", value: "-3d", want: date(time.March, 3)},
+		{name: "a week ahead", value: "+1w", want: date(time.March, 13)},
+		{name: "a weekday", value: "friday", want: date(time.March, 1)},
+		{name: "a short weekday", value: "fri", want: date(time.March, 1)},
+		{name: "today's weekday", value: "wednesday", want: date(time.March, 6)},
+		{name: "last weekday", value: "last friday", want: date(time.March, 1)},
+		{name: "last monday", value: "last mon", want: date(time.February, 26)},
+		{name: "iso", order: DateOrderMDY, value: "2024-07-12", want: date(time.July, 12)},
+		{name: "day first", order: DateOrderDMY, value: "12/07/2024", want: date(time.July, 12)},
+		{name: "month first", order: DateOrderMDY, value: "12/07/2024", want: date(time.December, 7)},
+		{name: "year first", order: DateOrderYMD, value: "2024/07/12", want: date(time.July, 12)},
+		{name: "dots", order: DateOrderDMY, value: "12.07.2024", want: date(time.July, 12)},
+		{name: "this year's", order: DateOrderDMY, value: "12/07", want: date(time.July, 12)},
+		{name: "this year's, month first", order: DateOrderMDY, value: "12/07", want: date(time.December, 7)},
+		{name: "leap day", order: DateOrderDMY, value: "29/02/2024", want: date(time.February, 29)},
+		{name: "no such day", order: DateOrderDMY, value: "31/02/2024", wantErr: true},
+		{name: "no such month", order: DateOrderDMY, value: "12/13/2024", wantErr: true},
+		{name: "two digit year", order: DateOrderDMY, value: "12/07/24", wantErr: true},
+		{name: "mixed separators", order: DateOrderDMY, value: "12/07-2024", wantErr: true},
+		{name: "year last in year first order", order: DateOrderYMD, value: "12/07/2024", wantErr: true},
+		{name: "unknown day", value: "someday", wantErr: true},
+		{name: "last of no day", value: "last week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := DateOrderEnum
+			t.Cleanup(func() { DateOrderEnum = saved })
+			if tt.order != "" {
+				DateOrderEnum = DateOrder(tt.order)
+			}
+
+			got, err := ParseDateAt(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDateAt(%q) = %s, want %s", tt.value, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationUnits are the lengths of the day and the week in Jira's time
+// tracking notation, 8 hours and 5 days unless the Jira instance says otherwise.
+type DurationUnits struct {
+	HoursPerDay float64
+	DaysPerWeek float64
+}
+
+var (
+	// DefaultUnits are Jira's own defaults.
+	DefaultUnits = DurationUnits{HoursPerDay: 8, DaysPerWeek: 5}
+	// WorkUnits are the units ParseDuration and FormatDuration go by.
+	WorkUnits = DefaultUnits
+)
+
+func (u DurationUnits) Validate() error {
+	if u.HoursPerDay <= 0 || u.HoursPerDay > 24 {
+		return fmt.Errorf("hours per day must be between 0 and 24, not %g", u.HoursPerDay)
+	}
+
+	if u.DaysPerWeek <= 0 || u.DaysPerWeek > 7 {
+		return fmt.Errorf("days per week must be between 0 and 7, not %g", u.DaysPerWeek)
+	}
+
+	return nil
+}
+
+func (u DurationUnits) unit(letter string) time.Duration {
+	day := time.Duration(u.HoursPerDay * float64(time.Hour))
+
+	switch letter {
+	case "w":
+		return time.Duration(u.DaysPerWeek * float64(day))
+	case "d":
+		return day
+	case "m":
+		return time.Minute
+	default:
+		return time.Hour
+	}
+}
+
+var durationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?|\.\d+)\s*([wdhm]?)\s*`)
+
+// ParseDuration reads a duration in Jira's notation, such as "1h 30m", "2d",
+// "90m" or "1w 2d", or a number of hours such as "2.5", to the second.
+func ParseDuration(text string) (time.Duration, error) {
+	rest := strings.ToLower(strings.TrimSpace(text))
+	if rest == "" {
+		return 0, fmt.Errorf("could not parse duration %q, expected i.e 2.5, 1h 30m, 90m or 2d", text)
+	}
+
+	var total float64
+	seen := map[string]bool{}
+	for rest != "" {
+		match := durationPart.FindStringSubmatch(rest)
+		// a bare number is hours, and only on its own
+		if match == nil || seen[match[2]] || match[2] == "" && (len(seen) > 0 || len(match[0]) != len(rest)) {
+			return 0, fmt.Errorf("could not parse duration %q, expected i.e 2.5, 1h 30m, 90m or 2d", text)
+		}
+		seen[match[2]] = true
+
+		amount, _ := strconv.ParseFloat(match[1], 64)
+		total += amount * float64(WorkUnits.unit(match[2]))
+		rest = rest[len(match[0]):]
+	}
+
+	return time.Duration(math.Round(total/float64(time.Second))) * time.Second, nil
+}
+
+// FormatDuration writes d in Jira's notation, i.e "1d 2h 30m", to the minute.
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	d = d.Round(time.Minute)
+	if d == 0 {
+		return "0m"
+	}
+
+	var parts []string
+	for _, letter := range []string{"w", "d", "h", "m"} {
+		unit := WorkUnits.unit(letter)
+		if count := d / unit; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", count, letter))
+			d -= count * unit
+		}
+	}
+
+	return sign + strings.Join(parts, " ")
+}
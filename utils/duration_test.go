@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// withUnits makes ParseDuration and FormatDuration go by units for the test.
+func withUnits(t *testing.T, units DurationUnits) {
+	t.Helper()
+
+	saved := WorkUnits
+	WorkUnits = units
+	t.Cleanup(func() { WorkUnits = saved })
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		units   DurationUnits
+		text    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "hours and minutes", units: DefaultUnits, text: "1h 30m", want: 90 * time.Minute},
+		{name: "no spaces", units: DefaultUnits, text: "1h30m", want: 90 * time.Minute},
+		{name: "minutes", units: DefaultUnits, text: "90m", want: 90 * time.Minute},
+		{name: "bare hours", units: DefaultUnits, text: "2.5", want: 150 * time.Minute},
+		{name: "any case", units: DefaultUnits, text: " 2H ", want: 2 * time.Hour},
+		{name: "a day", units: DefaultUnits, text: "1d", want: 8 * time.Hour},
+		{name: "a week", units: DefaultUnits, text: "1w", want: 40 * time.Hour},
+		{name: "a shorter day", units: DurationUnits{HoursPerDay: 7.5, DaysPerWeek: 5}, text: "1d", want: 450 * time.Minute},
+		{name: "a shorter week", units: DurationUnits{HoursPerDay: 7.5, DaysPerWeek: 4}, text: "1w 1d", want: 5 * 450 * time.Minute},
+		{name: "empty", units: DefaultUnits, text: "", wantErr: true},
+		{name: "unknown unit", units: DefaultUnits, text: "2y", wantErr: true},
+		{name: "unit twice", units: DefaultUnits, text: "1h 1h", wantErr: true},
+		{name: "bare number with units", units: DefaultUnits, text: "1h 30", wantErr: true},
+		{name: "words", units: DefaultUnits, text: "two hours", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withUnits(t, tt.units)
+
+			got, err := ParseDuration(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %s, want %s", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name  string
+		units DurationUnits
+		d     time.Duration
+		want  string
+	}{
+		{name: "zero", units: DefaultUnits, d: 0, want: "0m"},
+		{name: "hours and minutes", units: DefaultUnits, d: 90 * time.Minute, want: "1h 30m"},
+		{name: "to the minute", units: DefaultUnits, d: 90*time.Minute + 40*time.Second, want: "1h 31m"},
+		{name: "negative", units: DefaultUnits, d: -30 * time.Minute, want: "-30m"},
+		{name: "days and weeks", units: DefaultUnits, d: 50 * time.Hour, want: "1w 1d 2h"},
+		{name: "a shorter day", units: DurationUnits{HoursPerDay: 7.5, DaysPerWeek: 5}, d: 8 * time.Hour, want: "1d 30m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withUnits(t, tt.units)
+
+			if got := FormatDuration(tt.d); got != tt.want {
+				t.Errorf("FormatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationRoundTrip(t *testing.T) {
+	for _, units := range []DurationUnits{DefaultUnits, {HoursPerDay: 7.5, DaysPerWeek: 4}} {
+		withUnits(t, units)
+
+		for _, d := range []time.Duration{time.Minute, 90 * time.Minute, 8 * time.Hour, 37*time.Hour + 15*time.Minute, 100 * time.Hour} {
+			got, err := ParseDuration(FormatDuration(d))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != d {
+				t.Errorf("with %+v, %s came back as %s through %q", units, d, got, FormatDuration(d))
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "fmt"
+
+// AdjustEstimate tells Jira what to do with the remaining estimate of an
+// issue when work is logged on it.
+type AdjustEstimate string
+
+const (
+	// AdjustEstimateAuto reduces the remaining estimate by the time spent, Jira's default.
+	AdjustEstimateAuto string = "auto"
+	// AdjustEstimateLeave keeps the remaining estimate as it is.
+	AdjustEstimateLeave string = "leave"
+	// AdjustEstimateNew sets the remaining estimate to a new value.
+	AdjustEstimateNew string = "new"
+	// AdjustEstimateManual reduces the remaining estimate by a given amount.
+	AdjustEstimateManual string = "manual"
+)
+
+func (e *AdjustEstimate) String() string {
+	return string(*e)
+}
+
+func (e *AdjustEstimate) Set(v string) error {
+	switch v {
+	case AdjustEstimateAuto, AdjustEstimateLeave, AdjustEstimateNew, AdjustEstimateManual:
+		*e = AdjustEstimate(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s", []string{AdjustEstimateAuto, AdjustEstimateLeave, AdjustEstimateNew, AdjustEstimateManual})
+	}
+}
+
+func (e *AdjustEstimate) Type() string {
+	return "AdjustEstimate"
+}
+
+// IsAuto reports whether Jira is left to its default adjustment.
+func (e AdjustEstimate) IsAuto() bool {
+	return e == "" || e == AdjustEstimate(AdjustEstimateAuto)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogMode tells logwork what to do on days that already have work logged.
+type LogMode string
+
+const (
+	// LogModeAdd logs the time whatever was logged already.
+	LogModeAdd string = "add"
+	// LogModeTopUp logs what is missing for the day to reach the time.
+	LogModeTopUp string = "topup"
+	// LogModeSkipLogged leaves alone the days with any work logged.
+	LogModeSkipLogged string = "skip-logged"
+)
+
+func (e *LogMode) String() string {
+	return string(*e)
+}
+
+func (e *LogMode) Set(v string) error {
+	switch v {
+	case LogModeAdd, LogModeTopUp, LogModeSkipLogged:
+		*e = LogMode(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s", []string{LogModeAdd, LogModeTopUp, LogModeSkipLogged})
+	}
+}
+
+func (e *LogMode) Type() string {
+	return "LogMode"
+}
+
+// Missing is how much of want is still to be logged on a day that already
+// has logged time.
+func (e LogMode) Missing(want time.Duration, logged time.Duration) time.Duration {
+	switch string(e) {
+	case LogModeTopUp:
+		return want - logged
+	case LogModeSkipLogged:
+		if logged > 0 {
+			return 0
+		}
+	}
+
+	return want
+}
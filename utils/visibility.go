@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	VisibilityGroup string = "group"
+	VisibilityRole  string = "role"
+)
+
+// Visibility restricts who can see a worklog to the members of a group or
+// of a project role. The zero Visibility restricts nothing.
+type Visibility struct {
+	Kind  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (v *Visibility) String() string {
+	if v.IsZero() {
+		return ""
+	}
+
+	return v.Kind + ":" + v.Value
+}
+
+// Set reads group:<name> or role:<name>.
+func (v *Visibility) Set(s string) error {
+	kind, name, _ := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+
+	if kind != VisibilityGroup && kind != VisibilityRole || name == "" {
+		return fmt.Errorf("must be %s:<name> or %s:<name>", VisibilityGroup, VisibilityRole)
+	}
+
+	*v = Visibility{Kind: kind, Value: name}
+	return nil
+}
+
+func (v *Visibility) Type() string {
+	return "Visibility"
+}
+
+func (v *Visibility) IsZero() bool {
+	return v == nil || v.Kind == ""
+}